@@ -28,11 +28,13 @@ import (
 	"path/filepath"
 	stdruntime "runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"argus-cyclist/internal/domain"
 	"argus-cyclist/internal/service/ai"
 	"argus-cyclist/internal/service/ble"
+	"argus-cyclist/internal/service/dem"
 	"argus-cyclist/internal/service/fit"
 	"argus-cyclist/internal/service/gpx"
 	"argus-cyclist/internal/service/sim"
@@ -71,9 +73,13 @@ type App struct {
 	currentDist        float64
 	telemetryChan      chan domain.Telemetry
 	cancelSim          context.CancelFunc
+	cancelDiscovery    context.CancelFunc
+	discoveryDone      chan struct{}
+	discoveryMu        sync.Mutex // Guards cancelDiscovery/discoveryDone across binding calls
 
 	// Session metadata
 	currentRouteName     string    // Selected GPX route name
+	currentRouteID       uint      // Route library entry (0 = not a library route)
 	sessionStart         time.Time // Session start time (for duration calculation)
 	sessionActiveTime    float64
 	sessionPowerSum      uint64 // Sum of power samples (for average power)
@@ -90,6 +96,38 @@ type App struct {
 	hrAt2Min             int
 
 	aiService *ai.Service
+
+	// Head-to-head sessions with several trainers on this machine
+	multiRider *multiRiderSession
+
+	// Fixed-power companions the rider can draft behind
+	virtualRiders *sim.Group
+
+	// Wind along the route (nil = calm)
+	wind *sim.WindModel
+
+	// Past activity raced on the same route (nil = no ghost)
+	ghost *sim.Ghost
+
+	// SRTM tiles for elevation correction (created from the profile settings)
+	demService *dem.Service
+
+	// Route end behaviour and the laps of the current session
+	routeMode     string
+	laps          []domain.LapStats
+	lap           lapTracker
+	routeFinished bool
+
+	// Timed segments of the current route during a session; segmentsMu guards
+	// the slice, which the bindings change while the game loop times it
+	segments   []*segmentTimer
+	segmentsMu sync.Mutex
+
+	// Points of interest announced during a session
+	pois poiTracker
+
+	// Pacing plan shown as a target-power overlay (nil = none)
+	pacingPlan *sim.PacingPlan
 }
 
 type ExportPoint struct {
@@ -116,6 +154,8 @@ type SessionSummary struct {
 	NewFTP       int                `json:"new_ftp"`
 	NewMaxHR     int                `json:"new_max_hr"`
 	Gamification GamificationResult `json:"gamification"`
+	Ghost        *sim.GhostSummary  `json:"ghost,omitempty"`
+	Laps         []domain.LapStats  `json:"laps"`
 }
 
 // ActivityDetails contains the time-series data for the charts
@@ -157,6 +197,9 @@ func NewApp() *App {
 
 		storageService: store,
 		telemetryChan:  make(chan domain.Telemetry),
+		multiRider:     newMultiRiderSession(),
+		virtualRiders:  sim.NewGroup(),
+		routeMode:      gpx.CourseLoop,
 	}
 }
 
@@ -168,7 +211,14 @@ func (a *App) Startup(ctx context.Context) {
 // Shutdown is called by Wails when the app is closing.
 func (a *App) Shutdown(ctx context.Context) {
 	fmt.Println("Closing app: Disconnecting BLE...")
+	a.StopDeviceDiscovery()
 	a.trainerService.Disconnect()
+
+	a.multiRider.mu.Lock()
+	for _, r := range a.multiRider.riders {
+		r.trainer.Disconnect()
+	}
+	a.multiRider.mu.Unlock()
 }
 
 // OpenFileFolder opens the system file explorer at the given file location.
@@ -285,8 +335,7 @@ func (a *App) SelectLocalAccount(id string) (string, error) {
 	}
 
 	profile, _ := a.storageService.GetProfile()
-	a.physicsEngine.UserWeight = profile.Weight
-	a.physicsEngine.BikeWeight = profile.BikeWeight
+	configureEngine(a.physicsEngine, profile)
 
 	return "ok", nil
 }
@@ -324,8 +373,7 @@ func (a *App) EnsureEventModeProfile() (string, error) {
 		return "", err
 	}
 
-	a.physicsEngine.UserWeight = profile.Weight
-	a.physicsEngine.BikeWeight = profile.BikeWeight
+	configureEngine(a.physicsEngine, profile)
 
 	return "ok", nil
 }
@@ -367,11 +415,45 @@ func (a *App) UpdateUserProfile(u domain.UserProfile) string {
 	}
 
 	// Apply changes in real time
-	a.physicsEngine.UserWeight = u.Weight
-	a.physicsEngine.BikeWeight = u.BikeWeight
+	configureEngine(a.physicsEngine, u)
 	return "Profile Saved"
 }
 
+// configureEngine applies the rider's weights and bike/position physics to an engine.
+// Values left at zero in the profile fall back to the preset for the bike type.
+func configureEngine(e *sim.Engine, p domain.UserProfile) {
+	if p.Weight > 0 {
+		e.UserWeight = p.Weight
+	}
+	if p.BikeWeight > 0 {
+		e.BikeWeight = p.BikeWeight
+	}
+
+	params := sim.PresetParams(p.BikeType)
+	if p.CdA > 0 {
+		params.CdA = p.CdA
+	}
+	if p.Crr > 0 {
+		params.Crr = p.Crr
+	}
+	if p.Drivetrain > 0 {
+		params.Drivetrain = p.Drivetrain
+	}
+	params.TemperatureC = p.AirTemperature
+	params.PressureHPa = p.AirPressure
+	e.ApplyParams(params)
+
+	e.SpeedModel = sim.SpeedModelMomentum
+	if p.SpeedModel == sim.SpeedModelSteady {
+		e.SpeedModel = sim.SpeedModelSteady
+	}
+}
+
+// GetBikePresets returns the physics presets available for the profile's bike type.
+func (a *App) GetBikePresets() map[string]sim.Params {
+	return sim.BikePresets
+}
+
 // GetActivities returns recent recorded activities.
 func (a *App) GetActivities() []domain.Activity {
 	activities, err := a.storageService.GetRecentActivities(-1)
@@ -409,29 +491,202 @@ func (a *App) GetPowerCurve() []domain.PowerRecord {
 // SelectGPX opens a file dialog and loads the selected GPX route.
 func (a *App) SelectGPX() string {
 	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "Select the GPX Route", Filters: []runtime.FileFilter{{DisplayName: "GPX files", Pattern: "*.gpx"}},
+		Title: "Select the Route", Filters: []runtime.FileFilter{{DisplayName: "Route files (GPX, TCX, FIT, GeoJSON, KML)", Pattern: "*.gpx;*.tcx;*.fit;*.geojson;*.json;*.kml"}},
 	})
 	if err != nil || selection == "" {
 		return ""
 	}
+	return a.loadRouteFile(selection)
+}
 
-	points, err := a.gpxService.LoadAndProcess(selection)
+// loadRouteFile loads a route file and adds it to the route library.
+func (a *App) loadRouteFile(path string) string {
+	a.gpxService.SetElevationSource(a.elevationSource(false))
+	points, err := a.gpxService.LoadAndProcess(path)
 	if err != nil {
-		runtime.EventsEmit(a.ctx, "error", fmt.Sprintf("GPX error: %v", err))
+		runtime.EventsEmit(a.ctx, "error", fmt.Sprintf("Route error: %v", err))
 		return ""
 	}
 
-	a.currentRouteName = filepath.Base(selection)
+	a.currentRouteName = filepath.Base(path)
+	a.currentRouteID = 0
+	a.routeMode = gpx.CourseLoop
+	if route, err := a.registerRoute(path, a.gpxService); err == nil {
+		a.currentRouteID = route.ID
+		if gpx.ValidateCourseMode(route.Mode) == nil {
+			a.routeMode = route.Mode
+		}
+	} else {
+		fmt.Printf("[GPX] Route not added to the library: %v\n", err)
+	}
+	a.ghost = nil // A ghost only makes sense on the route it was recorded on
+	a.pacingPlan = nil
 
 	totalDistKm := 0.0
 	if len(points) > 0 {
 		totalDistKm = points[len(points)-1].Distance / 1000.0
 	}
-	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("GPX Loaded: %d points | %.2f km", len(points), totalDistKm))
+	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Route Loaded: %d points | %.2f km | %d course points", len(points), totalDistKm, len(a.gpxService.GetCoursePoints())))
+	if a.gpxService.ElevationMissing() {
+		runtime.EventsEmit(a.ctx, "route_warning", "The route has no elevation data: the profile is flat until it is corrected.")
+	}
 
 	return a.currentRouteName
 }
 
+// ====================
+// ELEVATION CORRECTION
+// ====================
+
+// elevationSource returns the DEM configured in the profile, or nil when it is
+// not configured (or automatic correction is off and force is false).
+func (a *App) elevationSource(force bool) gpx.ElevationSource {
+	p := a.GetUserProfile()
+	if p.DEMDirectory == "" || (!p.DEMCorrection && !force) {
+		return nil
+	}
+	if a.demService == nil || a.demService.Dir() != p.DEMDirectory {
+		a.demService = dem.NewService(p.DEMDirectory)
+	}
+	return a.demService
+}
+
+// SelectDEMDirectory picks the folder with the SRTM .hgt tiles and enables
+// automatic elevation correction for new routes. Only raw SRTM .hgt tiles
+// (1 or 3 arc-second) are read; GeoTIFF DEMs are not supported.
+func (a *App) SelectDEMDirectory() (string, error) {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{Title: "Select the SRTM (.hgt) Tiles Folder"})
+	if err != nil || dir == "" {
+		return "", err
+	}
+
+	p := a.GetUserProfile()
+	p.DEMDirectory = dir
+	p.DEMCorrection = true
+	if err := a.storageService.UpdateProfile(p); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// CorrectRouteElevation replaces the current route's elevations with DEM samples
+// from the configured .hgt tiles (GeoTIFF is not supported).
+func (a *App) CorrectRouteElevation() (*gpx.ElevationComparison, error) {
+	src := a.elevationSource(true)
+	if src == nil {
+		return nil, fmt.Errorf("no SRTM tiles folder configured")
+	}
+	comparison, err := a.gpxService.CorrectElevation(src)
+	if err != nil {
+		return nil, err
+	}
+	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Elevation corrected: gain %.0f m -> %.0f m", comparison.OriginalGain, comparison.CorrectedGain))
+	return comparison, nil
+}
+
+// RestoreRouteElevation puts back the elevations from the route file.
+func (a *App) RestoreRouteElevation() error {
+	return a.gpxService.RestoreElevation()
+}
+
+// GetElevationComparison returns the before/after profile of the current route (nil if not corrected).
+func (a *App) GetElevationComparison() *gpx.ElevationComparison {
+	return a.gpxService.GetElevationComparison()
+}
+
+// SetRouteProcessing resamples and re-smooths the loaded route
+// (smoothing: "points", "moving_average", "savitzky_golay" or "kalman";
+// resample_step is 0 to keep the file's points or at least 1 m).
+// For routes loaded from a file this writes "<route>.processing.json" next
+// to it, which is reused whenever the route loads again.
+func (a *App) SetRouteProcessing(opts gpx.ProcessingOptions) error {
+	if err := a.gpxService.SetProcessingOptions(opts); err != nil {
+		return err
+	}
+	opts = a.gpxService.GetProcessingOptions()
+	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Route processing: %s, %d points", opts.Smoothing, len(a.gpxService.GetAllPoints())))
+	return nil
+}
+
+// GetRouteProcessing returns the resampling/smoothing applied to the loaded route.
+func (a *App) GetRouteProcessing() gpx.ProcessingOptions {
+	return a.gpxService.GetProcessingOptions()
+}
+
+// GetRouteClimbs returns the categorised climbs (Cat 4 … HC) of the loaded route.
+func (a *App) GetRouteClimbs() []gpx.Climb {
+	return a.gpxService.GetClimbs()
+}
+
+// GetCoursePoints returns the points of interest of the loaded route
+// (TCX/FIT course points, GPX waypoints or a "<route>.pois.json" file).
+func (a *App) GetCoursePoints() []gpx.CoursePoint {
+	return a.gpxService.GetCoursePoints()
+}
+
+// SetCoursePoints replaces the points of interest of the loaded route
+// (kind: "sprint", "kom", "feed" or "note"). Points without a distance are
+// placed by latitude/longitude. Saved as "<route>.pois.json" and reused on load.
+func (a *App) SetCoursePoints(points []gpx.CoursePoint) error {
+	if err := a.gpxService.SetCoursePoints(points); err != nil {
+		return err
+	}
+	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Points of interest: %d", len(a.gpxService.GetCoursePoints())))
+	return nil
+}
+
+// GetRouteVideo returns the ride-along video of the loaded route (nil = none).
+// During a ride, telemetry carries the video position and playback rate.
+func (a *App) GetRouteVideo() *gpx.RouteVideo {
+	return a.gpxService.GetVideo()
+}
+
+// SetRouteVideo attaches a video and its sync map (video time <-> route
+// distance) to the loaded route; nil removes it. Saved as "<route>.video.json".
+func (a *App) SetRouteVideo(video *gpx.RouteVideo) error {
+	if err := a.gpxService.SetVideo(video); err != nil {
+		return err
+	}
+	if video == nil {
+		runtime.EventsEmit(a.ctx, "log", "Route video removed")
+	} else {
+		runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Route video: %s (%d sync points)", filepath.Base(video.File), len(video.Sync)))
+	}
+	return nil
+}
+
+// LoadRouteVideo attaches a video from a JSON sync file ({file, sync: [{time, distance}]}).
+func (a *App) LoadRouteVideo() error {
+	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select the Video Sync File", Filters: []runtime.FileFilter{{DisplayName: "JSON files", Pattern: "*.json"}},
+	})
+	if err != nil || selection == "" {
+		return err
+	}
+	video, err := gpx.ReadVideoFile(selection)
+	if err != nil {
+		return err
+	}
+	return a.SetRouteVideo(video)
+}
+
+// LoadSurfaceOverrides applies a JSON file of {from, to, surface} ranges to the loaded route.
+// A "<route>.surfaces.json" file next to the GPX is applied automatically on load.
+func (a *App) LoadSurfaceOverrides() error {
+	selection, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select the Surface Overrides", Filters: []runtime.FileFilter{{DisplayName: "JSON files", Pattern: "*.json"}},
+	})
+	if err != nil || selection == "" {
+		return err
+	}
+	return a.gpxService.LoadSurfaceOverrides(selection)
+}
+
+// SetSurfaceOverrides applies surface ranges edited in the UI to the loaded route.
+func (a *App) SetSurfaceOverrides(overrides []gpx.SurfaceOverride) error {
+	return a.gpxService.ApplySurfaceOverrides(overrides)
+}
+
 // LoadPredefinedKOMSegment injects the built-in event climb into the active route state.
 func (a *App) LoadPredefinedKOMSegment() (string, error) {
 	points, err := a.gpxService.LoadAndProcessContent(gpx.GetBuiltInKOMSegmentGPX())
@@ -440,6 +695,10 @@ func (a *App) LoadPredefinedKOMSegment() (string, error) {
 	}
 
 	a.currentRouteName = "KOM Event Segment"
+	a.currentRouteID = 0
+	a.routeMode = gpx.CourseLoop
+	a.ghost = nil
+	a.pacingPlan = nil
 
 	totalDistKm := 0.0
 	if len(points) > 0 {
@@ -450,6 +709,40 @@ func (a *App) LoadPredefinedKOMSegment() (string, error) {
 	return a.currentRouteName, nil
 }
 
+// GetRouteCatalogue lists the embedded climbs and benchmark routes.
+func (a *App) GetRouteCatalogue() []gpx.CatalogueRoute {
+	return gpx.Catalogue()
+}
+
+// LoadCatalogueRoute loads an embedded climb or benchmark route by ID.
+func (a *App) LoadCatalogueRoute(id string) (string, error) {
+	route, err := gpx.CatalogueRouteByID(id)
+	if err != nil {
+		return "", err
+	}
+	points, err := route.Build()
+	if err != nil {
+		return "", err
+	}
+
+	a.gpxService.SetPoints(points)
+	if route.Kind == gpx.CatalogueClimb {
+		summit := points[len(points)-1]
+		kom := gpx.CoursePoint{Name: route.Name, Kind: gpx.POIKOM, Distance: summit.Distance, Latitude: summit.Latitude, Longitude: summit.Longitude}
+		if err := a.gpxService.SetCoursePoints([]gpx.CoursePoint{kom}); err != nil {
+			return "", err
+		}
+	}
+	a.currentRouteName = route.Name
+	a.currentRouteID = 0
+	a.routeMode = gpx.CourseLoop
+	a.ghost = nil
+	a.pacingPlan = nil
+	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Catalogue route loaded: %s | %.2f km | %.0f m", route.Name, route.Distance/1000, route.ElevationGain))
+
+	return a.currentRouteName, nil
+}
+
 // SetDirectGrade sets the trainer resistance to a direct grade percentage (hardcoded control).
 func (a *App) SetDirectGrade(grade float64) error {
 	a.currentDirectGrade = grade
@@ -470,58 +763,54 @@ func (a *App) SetKOMGradeSchedule(grades string) (string, error) {
 		}
 	}
 
-	numPoints := 30
-	routeLen := 3000.0
-	points := make([]domain.RoutePoint, numPoints)
-
-	startLat := -23.560000
-	startLon := -46.650000
-	startEle := 760.0
-
-	for i := 0; i < numPoints; i++ {
-		pct := float64(i) / float64(numPoints-1)
-
-		gradeIdx := pct * float64(len(gradeSchedule)-1)
-		idx1 := int(gradeIdx)
-		idx2 := idx1 + 1
-		if idx2 >= len(gradeSchedule) {
-			idx2 = len(gradeSchedule) - 1
-		}
-		t := gradeIdx - float64(idx1)
-		grade := gradeSchedule[idx1] + t*(gradeSchedule[idx2]-gradeSchedule[idx1])
-
-		lat := startLat - (float64(i) * 0.0012)
-		lon := startLon - (float64(i) * 0.0012)
-		dist := float64(i) * (routeLen / float64(numPoints))
-
-		ele := startEle
-		if i > 0 {
-			segLen := routeLen / float64(numPoints)
-			ele = points[i-1].Elevation + segLen*(grade/100)
-		}
-
-		points[i] = domain.RoutePoint{
-			Latitude:  lat,
-			Longitude: lon,
-			Elevation: ele,
-			Distance:  dist,
-			Grade:     grade,
-		}
+	points, err := gpx.SynthesizeRoute(gpx.GradeScheduleLegs(gradeSchedule, 3000, 0), gpx.KOMOrigin)
+	if err != nil {
+		return "", err
 	}
 
 	a.gpxService.SetPoints(points)
 	a.currentRouteName = "KOM Event Segment"
+	a.currentRouteID = 0
+	a.routeMode = gpx.CourseLoop
+	a.ghost = nil
+	a.pacingPlan = nil
 	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("KOM route set: %d points", len(points)))
 
 	return a.currentRouteName, nil
 }
 
+// BuildRoute creates a synthetic route from (distance, grade) or (distance,
+// target elevation) legs and loads it. opts may be nil to start at the default origin.
+func (a *App) BuildRoute(name string, legs []gpx.RouteLeg, opts *gpx.SynthOptions) (string, error) {
+	origin := gpx.KOMOrigin
+	if opts != nil {
+		origin = *opts
+	}
+	points, err := gpx.SynthesizeRoute(legs, origin)
+	if err != nil {
+		return "", err
+	}
+	if name == "" {
+		name = fmt.Sprintf("route_%s", time.Now().Format("20060102_1504"))
+	}
+
+	a.gpxService.SetPoints(points)
+	a.currentRouteName = name
+	a.currentRouteID = 0
+	a.routeMode = gpx.CourseLoop
+	a.ghost = nil
+	a.pacingPlan = nil
+	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Route built: %d points | %.2f km", len(points), points[len(points)-1].Distance/1000))
+	return a.currentRouteName, nil
+}
+
 // GetRoutePath returns all processed GPX points.
 func (a *App) GetRoutePath() []domain.RoutePoint {
 	return a.gpxService.GetAllPoints()
 }
 
 // GetElevationProfile returns only elevation values for charting.
+// Long routes should use GetRouteProfile, which is downsampled.
 func (a *App) GetElevationProfile() []float64 {
 	points := a.gpxService.GetAllPoints()
 	elevations := make([]float64, len(points))
@@ -531,6 +820,46 @@ func (a *App) GetElevationProfile() []float64 {
 	return elevations
 }
 
+// GetRouteProfile returns the downsampled, distance-indexed elevation profile
+// of the loaded route with its grade bands (method: "lttb" or "step").
+func (a *App) GetRouteProfile(opts gpx.ProfileOptions) (gpx.ElevationProfile, error) {
+	return a.gpxService.ElevationProfile(opts)
+}
+
+// RiderProfile is the elevation profile around the rider for the fullscreen HUD.
+type RiderProfile struct {
+	gpx.ElevationProfile
+	Position float64 `json:"position"` // Rider route position (m)
+	Reversed bool    `json:"reversed"` // Riding the route backwards: "ahead" is towards the start
+}
+
+// GetRiderProfile returns the elevation profile from behind to ahead metres
+// around the rider's current position (clamped to the route ends).
+func (a *App) GetRiderProfile(points int, behind, ahead float64) (RiderProfile, error) {
+	total := a.gpxService.GetTotalDistance()
+	if total <= 0 {
+		return RiderProfile{}, fmt.Errorf("no route loaded")
+	}
+	if behind < 0 || ahead < 0 || behind+ahead <= 0 {
+		return RiderProfile{}, fmt.Errorf("invalid profile window")
+	}
+
+	course := gpx.Locate(a.routeMode, a.currentDist, total)
+	from, to := course.Position-behind, course.Position+ahead
+	if course.Reversed {
+		from, to = course.Position-ahead, course.Position+behind
+	}
+	from, to = math.Max(0, from), math.Min(total, to)
+	if to <= from {
+		return RiderProfile{}, fmt.Errorf("invalid profile window")
+	}
+	profile, err := a.gpxService.ElevationProfile(gpx.ProfileOptions{Points: points, From: from, To: to})
+	if err != nil {
+		return RiderProfile{}, err
+	}
+	return RiderProfile{ElevationProfile: profile, Position: course.Position, Reversed: course.Reversed}, nil
+}
+
 // SaveGeneratedGPX receives points from the frontend and creates a GPX file.
 func (a *App) SaveGeneratedGPX(name string, points []ExportPoint) string {
 	if name == "" {
@@ -556,17 +885,72 @@ func (a *App) SaveGeneratedGPX(name string, points []ExportPoint) string {
   </trk>
 </gpx>`)
 
-	// 2. Ensures that the routes folder exists.
+	result := saveRouteFile(name, "gpx", []byte(sb.String()))
+	if path, ok := strings.CutPrefix(result, "Saved: "); ok {
+		a.addRouteToLibrary(path)
+	}
+	return result
+}
+
+// ExportRouteGPX saves the current route (e.g. a built one) as GPX in the routes
+// folder and adds it to the route library.
+func (a *App) ExportRouteGPX(name string) string {
+	points := a.gpxService.GetAllPoints()
+	if len(points) == 0 {
+		return "Error saving file: no route loaded"
+	}
+	result := saveRouteFile(a.exportName(name), "gpx", gpx.EncodeGPX(a.exportName(name), points))
+	if path, ok := strings.CutPrefix(result, "Saved: "); ok {
+		a.addRouteToLibrary(path)
+	}
+	return result
+}
+
+// ExportRouteGeoJSON saves the current route as a GeoJSON LineString in the routes folder.
+func (a *App) ExportRouteGeoJSON(name string) string {
+	points := a.gpxService.GetAllPoints()
+	if len(points) == 0 {
+		return "Error saving file: no route loaded"
+	}
+	data, err := gpx.EncodeGeoJSON(a.exportName(name), points)
+	if err != nil {
+		return "Error saving file: " + err.Error()
+	}
+	return saveRouteFile(a.exportName(name), "geojson", data)
+}
+
+// ExportRouteKML saves the current route as a KML LineString in the routes folder.
+func (a *App) ExportRouteKML(name string) string {
+	points := a.gpxService.GetAllPoints()
+	if len(points) == 0 {
+		return "Error saving file: no route loaded"
+	}
+	return saveRouteFile(a.exportName(name), "kml", gpx.EncodeKML(a.exportName(name), points))
+}
+
+// exportName defaults to the current route name without its extension.
+func (a *App) exportName(name string) string {
+	if name != "" {
+		return name
+	}
+	if a.currentRouteName != "" {
+		return strings.TrimSuffix(a.currentRouteName, filepath.Ext(a.currentRouteName))
+	}
+	return fmt.Sprintf("route_%s", time.Now().Format("20060102_1504"))
+}
+
+// saveRouteFile writes <name>.<ext> into the routes folder.
+func saveRouteFile(name, ext string, data []byte) string {
+	// Ensures that the routes folder exists.
 	routesDir := "routes"
 	if _, err := os.Stat(routesDir); os.IsNotExist(err) {
 		os.Mkdir(routesDir, 0755)
 	}
 
-	// 3. Save the file.
-	filename := fmt.Sprintf("%s.gpx", name)
+	filename := fmt.Sprintf("%s.%s", name, ext)
 	fullPath := filepath.Join(routesDir, filename)
 
-	err := os.WriteFile(fullPath, []byte(sb.String()), 0644)
+	err := os.WriteFile(fullPath, data, 0644)
 	if err != nil {
 		return "Error saving file: " + err.Error()
 	}
@@ -574,6 +958,11 @@ func (a *App) SaveGeneratedGPX(name string, points []ExportPoint) string {
 	return "Saved: " + fullPath
 }
 
+// RouteElevationMissing reports whether the loaded route has no elevation data.
+func (a *App) RouteElevationMissing() bool {
+	return a.gpxService.ElevationMissing()
+}
+
 // =======================
 // WINDOW & DEVICE CONTROL
 // =======================
@@ -585,6 +974,8 @@ func (a *App) ToggleAlwaysOnTop(on bool) {
 
 // ConnectTrainer connects to the training roller using the MAC address selected by the user.
 func (a *App) ConnectTrainer(macAddress string) (string, error) {
+	a.StopDeviceDiscovery()
+
 	if a.isTrainerConnected && a.trainerService != nil {
 		a.trainerService.Disconnect()
 	}
@@ -650,6 +1041,8 @@ func (a *App) ConnectHeartRate(macAddress string) (string, error) {
 		return "HR Already Connected", nil
 	}
 
+	a.StopDeviceDiscovery()
+
 	statusCallback := func(stage string, data string) {
 		runtime.EventsEmit(a.ctx, "ble_connection_status", map[string]string{"stage": stage, "msg": data})
 	}
@@ -699,6 +1092,14 @@ func (a *App) startSession() string {
 		return "Error: Trainer Disconnected"
 	}
 
+	a.multiRider.mu.Lock()
+	multiRunning := a.multiRider.isRunning
+	a.multiRider.mu.Unlock()
+	if multiRunning {
+		runtime.EventsEmit(a.ctx, "error", "A multi-rider session is already running.")
+		return "Error: Multi-rider session active"
+	}
+
 	if a.activeWorkout != nil {
 		a.isInWorkout = true
 		runtime.EventsEmit(a.ctx, "log", "Workout Mode: ACTIVATED")
@@ -708,6 +1109,7 @@ func (a *App) startSession() string {
 
 	a.sessionPowerData = []int{}
 	a.sessionHRData = []int{}
+	a.virtualRiders.Restart(a.currentDist)
 	a.currentDist = 0
 	a.sessionStart = time.Now()
 	a.sessionActiveTime = 0
@@ -717,6 +1119,18 @@ func (a *App) startSession() string {
 	a.workoutIntensity = 1.0
 	a.sessionElevationGain = 0.0
 	a.lastAltitude = -9999.0
+	a.physicsEngine.Reset()
+	if a.ghost != nil {
+		a.ghost.Start(0, 0)
+	}
+	a.laps = nil
+	a.lap.reset(a.sessionStart, 0, 0, 0)
+	a.routeFinished = false
+	segments := a.loadSegmentTimers()
+	a.segmentsMu.Lock()
+	a.segments = segments
+	a.segmentsMu.Unlock()
+	a.pois = newPOITracker()
 
 	// Clear the .FIT file array from memory to avoid altering routes.
 	if a.fitService != nil {
@@ -913,6 +1327,7 @@ func (a *App) FinishSession() (SessionSummary, error) {
 		HRR1:              hrr1,
 		HRR2:              hrr2,
 		UploadedToStrava:  false,
+		RouteID:           a.currentRouteID,
 	}
 
 	gamificationResult := a.ProcessGamification(activity)
@@ -927,6 +1342,13 @@ func (a *App) FinishSession() (SessionSummary, error) {
 		a.updateComponentUsage(distKm)
 	}
 
+	// Close the lap in progress so the FIT file and the summary cover the whole session
+	if a.lap.ticks > 0 {
+		a.completeLap(time.Now(), false)
+	}
+
+	a.recordRouteRide(activity.CreatedAt)
+
 	if err := a.fitService.Save(fullPath); err != nil {
 		runtime.EventsEmit(a.ctx, "error", "Error saving FIT file")
 	} else {
@@ -946,13 +1368,19 @@ func (a *App) FinishSession() (SessionSummary, error) {
 
 	runtime.EventsEmit(a.ctx, "status_change", "IDLE")
 
-	return SessionSummary{
+	summary := SessionSummary{
 		Activity:     activity,
 		Zones:        zones,
 		NewFTP:       newFTP,
 		NewMaxHR:     sessionMaxHR,
 		Gamification: gamificationResult,
-	}, nil
+		Laps:         a.GetLaps(),
+	}
+	if a.ghost != nil {
+		ghostSummary := a.ghost.Summary()
+		summary.Ghost = &ghostSummary
+	}
+	return summary, nil
 }
 
 // DiscardSession cancels the current session without saving any data.
@@ -1035,7 +1463,11 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 	lastSentPower := -1
 	lastSentGrade := -999.0
 	currentMode := ""
-	lastRoutePos := -1.0
+	lastLap := -1
+	var lastSimParams domain.SimulationParams
+	var lastGhostEmit time.Time
+	lastWorkoutText := -1.0 // Workout time up to which text events were shown
+	lastVideoTime := 0.0    // Video time of the last forward position
 
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -1095,6 +1527,7 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 
 			if a.isPaused {
 				lastUpdate = now
+				a.physicsEngine.Reset()
 				runtime.EventsEmit(a.ctx, "telemetry_update", domain.Telemetry{
 					Power: currentPower, Cadence: currentCadence, HeartRate: currentHR,
 					Speed: 0, TotalDistance: a.currentDist, CurrentGrade: 0,
@@ -1128,11 +1561,7 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 			// ===================================
 
 			// We obtain the current point of the route to determine the slope and coordinates.
-			routePos := a.currentDist
-			if totalRouteDistance > 0 {
-				routePos = math.Mod(a.currentDist, totalRouteDistance)
-			}
-			routePoint := a.gpxService.GetPointAtDistance(routePos)
+			routePoint, course := a.coursePoint(a.currentDist, totalRouteDistance)
 
 			// Variables for the workout state
 			targetWatts := 0
@@ -1144,12 +1573,8 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 
 			if a.isInWorkout && a.activeWorkout != nil {
 				// --- ERG MODE (WORKOUT ACTIVE) ---
-
-				// Ensure the trainer is in ERG mode.
-				if currentMode != "ERG" {
-					a.trainerService.SetTrainerMode("ERG")
-					currentMode = "ERG"
-				}
+				// Each segment sets its own mode: ERG, or SIM for free rides.
+				// A mode change resets the last targets so they are sent again.
 
 				elapsed := a.sessionActiveTime - a.workoutStartTimeOffset
 				timeAccumulator := 0.0
@@ -1169,6 +1594,9 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 						targetFactor := seg.StartFactor + (seg.EndFactor-seg.StartFactor)*progress
 
 						userFTP := float64(a.GetUserProfile().FTP)
+						if a.activeWorkout.FTPOverride > 0 {
+							userFTP = float64(a.activeWorkout.FTPOverride)
+						}
 						if userFTP == 0 {
 							userFTP = 200
 						}
@@ -1178,6 +1606,7 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 							if currentMode != "SIM" {
 								a.trainerService.SetTrainerMode("SIM")
 								currentMode = "SIM"
+								lastSentPower, lastSentGrade = -1, -999.0
 							}
 							if math.Abs(1.0-lastSentGrade) > 0.1 {
 								a.trainerService.SetGrade(1.0)
@@ -1187,6 +1616,7 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 							if currentMode != "ERG" {
 								a.trainerService.SetTrainerMode("ERG")
 								currentMode = "ERG"
+								lastSentPower, lastSentGrade = -1, -999.0
 							}
 							if targetWatts != lastSentPower {
 								a.trainerService.SetPower(float64(targetWatts))
@@ -1202,6 +1632,15 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 					timeAccumulator += segDur
 				}
 
+				// On-screen messages (the workout restarts when it is repeated or replaced)
+				if elapsed < lastWorkoutText {
+					lastWorkoutText = -1
+				}
+				for _, cue := range workout.TextEventsBetween(a.activeWorkout, lastWorkoutText, elapsed) {
+					runtime.EventsEmit(a.ctx, "workout_text", cue)
+				}
+				lastWorkoutText = elapsed
+
 				// Global progress calculation
 				if a.activeWorkout.TotalDuration > 0 {
 					completionPct = (elapsed / float64(a.activeWorkout.TotalDuration)) * 100
@@ -1223,6 +1662,7 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 				if currentMode != "SIM" {
 					a.trainerService.SetTrainerMode("SIM")
 					currentMode = "SIM"
+					lastSentPower, lastSentGrade = -1, -999.0
 				}
 
 				// Use direct grade if set (KOM mode), otherwise use GPX route grade
@@ -1248,18 +1688,64 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 				activeGrade = a.currentDirectGrade
 			}
 
-			speedMs := a.physicsEngine.CalculateSpeed(float64(currentPower), activeGrade)
+			// Virtual riders move first, so the slipstream reflects their new positions.
+			if a.virtualRiders.Len() > 0 {
+				a.virtualRiders.Step(a.routeLookup(totalRouteDistance), dt)
+			}
+			a.physicsEngine.DraftReduction = a.virtualRiders.DraftFor(a.currentDist)
+
+			headwind, crosswind := a.windAt(course, a.sessionActiveTime)
+			a.physicsEngine.Headwind = headwind
+			a.physicsEngine.Surface = routePoint.Surface
+
+			// Keep the trainer's aerodynamic parameters in sync (only when they change noticeably)
+			simParams := domain.SimulationParams{
+				WindResistance: a.physicsEngine.CdA * a.physicsEngine.AirDensity(routePoint.Elevation),
+				DraftingFactor: 1 - a.physicsEngine.DraftReduction,
+				WindSpeed:      headwind,
+				Crr:            a.physicsEngine.RollingCrr(),
+			}
+			if currentMode == "SIM" &&
+				(math.Abs(simParams.DraftingFactor-lastSimParams.DraftingFactor) > 0.02 ||
+					math.Abs(simParams.WindResistance-lastSimParams.WindResistance) > 0.01 ||
+					math.Abs(simParams.WindSpeed-lastSimParams.WindSpeed) > 0.3 ||
+					math.Abs(simParams.Crr-lastSimParams.Crr) > 0.0005) {
+				a.trainerService.SetSimulationParams(simParams)
+				lastSimParams = simParams
+			}
+
+			speedMs := a.physicsEngine.Step(float64(currentPower), activeGrade, routePoint.Elevation, dt)
 			a.currentDist += speedMs * dt
+			if a.routeMode == gpx.CourseFinish && totalRouteDistance > 0 && a.currentDist > totalRouteDistance {
+				a.currentDist = totalRouteDistance
+			}
 
 			if a.lastAltitude != -9999.0 {
-				if lastRoutePos == -1.0 || routePos >= lastRoutePos {
+				// Skip the jump back to the start elevation when a loop wraps
+				if lastLap == -1 || course.Lap == lastLap {
 					if routePoint.Elevation > a.lastAltitude {
 						a.sessionElevationGain += (routePoint.Elevation - a.lastAltitude)
 					}
 				}
 			}
 			a.lastAltitude = routePoint.Elevation
-			lastRoutePos = routePos
+			lastLap = course.Lap
+
+			// Lap detection
+			a.lap.sample(currentPower, currentHR, currentCadence, speedMs*3.6)
+			if totalRouteDistance > 0 {
+				after := gpx.Locate(a.routeMode, a.currentDist, totalRouteDistance)
+				for len(a.laps) < after.Lap {
+					lap := a.completeLap(now, true)
+					runtime.EventsEmit(a.ctx, "lap_completed", lap)
+				}
+				if after.Finished && !a.routeFinished {
+					a.routeFinished = true
+					runtime.EventsEmit(a.ctx, "route_finished", a.GetLaps())
+				}
+				a.timeSegments(course, after, totalRouteDistance, dt, currentPower, currentHR)
+				a.checkPOIs(after, now)
+			}
 
 			// ==============================
 			// NOTIFICATIONS FOR THE FRONTEND
@@ -1272,17 +1758,54 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 				Latitude: routePoint.Latitude, Longitude: routePoint.Longitude, Altitude: routePoint.Elevation,
 				ElevationGain: a.sessionElevationGain,
 				RiderWeight: a.physicsEngine.UserWeight,
+				DraftSaving: a.physicsEngine.DraftReduction,
+				Headwind: headwind, Crosswind: crosswind,
+				Surface: surfaceName(routePoint.Surface),
+			}
+			if climb, remaining := a.gpxService.ClimbAt(course); climb != nil {
+				fullTelemetry.ClimbIndex = climb.Index
+				fullTelemetry.ClimbCategory = climb.Category
+				fullTelemetry.ClimbRemaining = remaining
+			}
+			a.segmentTelemetry(&fullTelemetry)
+			if a.pacingPlan != nil && !course.Reversed {
+				fullTelemetry.PlanPower = int(a.pacingPlan.TargetAt(course.Position))
+			}
+			if video := a.gpxService.GetVideo(); video != nil {
+				// The video only plays forwards: on a reversed leg it holds the
+				// last forward frame instead of scrubbing back along the return
+				if course.Reversed {
+					fullTelemetry.VideoTime = lastVideoTime
+				} else {
+					fullTelemetry.VideoTime, fullTelemetry.VideoRate = video.Playback(course.Position, speedMs)
+					lastVideoTime = fullTelemetry.VideoTime
+				}
 			}
 			a.fitService.AddRecord(fullTelemetry)
 			runtime.EventsEmit(a.ctx, "telemetry_update", fullTelemetry)
 
+			if a.virtualRiders.Len() > 0 {
+				runtime.EventsEmit(a.ctx, "virtual_riders_update", a.virtualRiders.States(a.currentDist))
+			}
+
+			if a.ghost != nil {
+				ghost := a.ghostState(totalRouteDistance)
+				if now.Sub(lastGhostEmit) >= time.Second {
+					runtime.EventsEmit(a.ctx, "ghost_update", ghost)
+					lastGhostEmit = now
+				}
+			}
+
 			// 2. Training State Package (Only if you are training)
 			if a.isInWorkout {
 				isFreeRide := false
 				segDuration := 0
+				cadenceLow, cadenceHigh := 0, 0
 				if currentSegmentIdx >= 0 && currentSegmentIdx < len(a.activeWorkout.Segments) {
-					isFreeRide = a.activeWorkout.Segments[currentSegmentIdx].FreeRide
-					segDuration = a.activeWorkout.Segments[currentSegmentIdx].DurationSeconds
+					seg := a.activeWorkout.Segments[currentSegmentIdx]
+					isFreeRide = seg.FreeRide
+					segDuration = seg.DurationSeconds
+					cadenceLow, cadenceHigh = seg.CadenceLow, seg.CadenceHigh
 				}
 
 				workoutState := domain.WorkoutState{
@@ -1296,6 +1819,8 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 					CompletionPercent: completionPct,
 					IntensityPct:      int(a.workoutIntensity * 100),
 					IsFreeRide:        isFreeRide,
+					CadenceLow:        cadenceLow,
+					CadenceHigh:       cadenceHigh,
 				}
 				runtime.EventsEmit(a.ctx, "workout_status", workoutState)
 			}
@@ -1303,6 +1828,125 @@ func (a *App) gameLoop(ctx context.Context, input <-chan domain.Telemetry) {
 	}
 }
 
+// routeLookup exposes the loaded route to the simulation packages. Distances
+// wrap around the route like the rider's own position does.
+func (a *App) routeLookup(totalRouteDistance float64) sim.RouteLookup {
+	return func(distance float64) (float64, float64) {
+		p, _ := a.coursePoint(distance, totalRouteDistance)
+		grade := p.Grade
+		if a.currentDirectGrade != 0 {
+			grade = a.currentDirectGrade
+		}
+		return grade, p.Elevation
+	}
+}
+
+// surfaceName reports untagged route sections as asphalt.
+func surfaceName(surface string) string {
+	if surface == "" {
+		return domain.SurfaceAsphalt
+	}
+	return surface
+}
+
+// windAt resolves the wind model against the route heading at a course position.
+// Returns the headwind and crosswind components in m/s.
+func (a *App) windAt(course gpx.CoursePosition, elapsed float64) (float64, float64) {
+	if a.wind == nil || a.wind.Mode == sim.WindNone {
+		return 0, 0
+	}
+	speed, direction := a.wind.At(course.Position, elapsed)
+	heading := a.gpxService.GetHeadingAtDistance(course.Position)
+	if course.Reversed {
+		heading = math.Mod(heading+180.0, 360.0)
+	}
+	return sim.WindComponents(speed, direction, heading)
+}
+
+// ====
+// WIND
+// ====
+
+// SetWind configures the wind model (none, constant, gusty or a schedule along the route).
+func (a *App) SetWind(model sim.WindModel) error {
+	if err := model.Validate(); err != nil {
+		return err
+	}
+	if model.Mode == sim.WindNone {
+		a.wind = nil
+		return nil
+	}
+	a.wind = &model
+	fmt.Printf("[SIM] Wind set: mode=%s speed=%.1f m/s from %.0f°\n", model.Mode, model.Speed, model.Direction)
+	return nil
+}
+
+// GetWind returns the current wind model.
+func (a *App) GetWind() sim.WindModel {
+	if a.wind == nil {
+		return sim.WindModel{Mode: sim.WindNone}
+	}
+	return *a.wind
+}
+
+// =====================
+// VIRTUAL RIDERS (DRAFT)
+// =====================
+
+// AddVirtualRiders spawns fixed-power companions on the route, the first one
+// gapMeters ahead of the rider (negative values spawn them behind).
+func (a *App) AddVirtualRiders(count int, watts float64, gapMeters float64) []sim.VirtualRiderState {
+	if count <= 0 || watts <= 0 {
+		return a.virtualRiders.States(a.currentDist)
+	}
+	profile := a.GetUserProfile()
+	a.virtualRiders.Spawn(count, watts, a.currentDist+gapMeters, profile.Weight, profile.BikeWeight)
+	return a.virtualRiders.States(a.currentDist)
+}
+
+// ClearVirtualRiders removes all companions from the route.
+func (a *App) ClearVirtualRiders() {
+	a.virtualRiders.Clear()
+	a.physicsEngine.DraftReduction = 0
+}
+
+// AddPacer spawns a pacer bot at the rider's position, riding at a fixed power,
+// W/kg, fraction of the user's FTP, or the power that covers one lap of the
+// route in a target time. Pacers use the user's bike setup.
+func (a *App) AddPacer(cfg sim.PacerConfig) ([]sim.VirtualRiderState, error) {
+	profile := a.GetUserProfile()
+	e := sim.NewEngine(profile.Weight, profile.BikeWeight)
+	configureEngine(e, profile)
+	if cfg.Weight > 0 {
+		e.UserWeight = cfg.Weight
+	}
+
+	total := a.gpxService.GetTotalDistance()
+	var route sim.RouteLookup
+	if total > 0 {
+		route = a.routeLookup(total)
+	}
+
+	watts, err := sim.PacerPower(cfg, e, float64(profile.FTP), route, a.currentDist, total)
+	if err != nil {
+		return nil, err
+	}
+	a.virtualRiders.SpawnPacer(cfg, e, watts, a.currentDist)
+	fmt.Printf("[SIM] Pacer added: mode=%s value=%.2f -> %.0f W\n", cfg.Mode, cfg.Value, watts)
+	return a.virtualRiders.States(a.currentDist), nil
+}
+
+// RemoveVirtualRider removes one companion or pacer from the route.
+func (a *App) RemoveVirtualRider(id int) []sim.VirtualRiderState {
+	a.virtualRiders.Remove(id)
+	return a.virtualRiders.States(a.currentDist)
+}
+
+// GetVirtualRiders returns the companions' positions and gaps to the rider.
+func (a *App) GetVirtualRiders() []sim.VirtualRiderState {
+	return a.virtualRiders.States(a.currentDist)
+}
+
 // SetPowerTarget sets the target power for ERG mode.
 func (a *App) SetPowerTarget(watts float64) {
 	if a.trainerService != nil {
@@ -1369,6 +2013,9 @@ func (a *App) LoadWorkout() string {
 		runtime.EventsEmit(a.ctx, "error", err.Error())
 		return ""
 	}
+	for _, warning := range wo.Warnings {
+		runtime.EventsEmit(a.ctx, "log", "Workout: "+warning)
+	}
 
 	a.activeWorkout = wo
 
@@ -1444,6 +2091,8 @@ func (a *App) ChangeWorkoutIntensity(delta int) int {
 
 // ScanTrainers is called by the frontend to search for devices.
 func (a *App) ScanTrainers() []domain.BLEDevice {
+	a.StopDeviceDiscovery()
+
 	if _, isReal := a.trainerService.(*ble.RealService); !isReal {
 		a.trainerService = ble.NewRealService()
 	}
@@ -1463,6 +2112,8 @@ func (a *App) ScanTrainers() []domain.BLEDevice {
 
 // ScanHeartRate is called by the frontend to search for heart rate monitors.
 func (a *App) ScanHeartRate() []domain.BLEDevice {
+	a.StopDeviceDiscovery()
+
 	if _, isReal := a.trainerService.(*ble.RealService); !isReal {
 		a.trainerService = ble.NewRealService()
 	}
@@ -1480,6 +2131,65 @@ func (a *App) ScanHeartRate() []domain.BLEDevice {
 	return []domain.BLEDevice{}
 }
 
+// StartDeviceDiscovery starts a continuous scan covering every sensor role
+// (trainers, power meters, CSC and HR monitors). Devices are streamed to the
+// frontend through "ble_device_discovered" events until StopDeviceDiscovery is called.
+func (a *App) StartDeviceDiscovery() error {
+	a.discoveryMu.Lock()
+	defer a.discoveryMu.Unlock()
+	a.stopDiscovery()
+
+	if _, isReal := a.trainerService.(*ble.RealService); !isReal {
+		a.trainerService = ble.NewRealService()
+	}
+	realSvc, ok := a.trainerService.(*ble.RealService)
+	if !ok {
+		return fmt.Errorf("bluetooth discovery is not available")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	a.cancelDiscovery = cancel
+	a.discoveryDone = done
+
+	go func() {
+		defer close(done)
+		err := realSvc.Discover(ctx, func(d domain.DiscoveredDevice) {
+			runtime.EventsEmit(a.ctx, "ble_device_discovered", d)
+		})
+		if err != nil {
+			fmt.Println("[BLE] Discovery error:", err)
+			runtime.EventsEmit(a.ctx, "error", err.Error())
+		}
+		runtime.EventsEmit(a.ctx, "ble_discovery_stopped")
+	}()
+
+	return nil
+}
+
+// StopDeviceDiscovery cancels a running discovery scan and waits for the
+// adapter to be released, so a connection scan can start right after.
+func (a *App) StopDeviceDiscovery() {
+	a.discoveryMu.Lock()
+	defer a.discoveryMu.Unlock()
+	a.stopDiscovery()
+}
+
+// stopDiscovery is StopDeviceDiscovery with discoveryMu held.
+func (a *App) stopDiscovery() {
+	if a.cancelDiscovery == nil {
+		return
+	}
+	a.cancelDiscovery()
+	select {
+	case <-a.discoveryDone:
+	case <-time.After(3 * time.Second):
+		fmt.Println("[BLE] Warning: discovery did not stop in time")
+	}
+	a.cancelDiscovery = nil
+	a.discoveryDone = nil
+}
+
 func (a *App) GetActivityDetails(filePath string) (fit.ActivityDetails, error) {
 	details, err := a.fitService.ParseActivity(filePath)
 	if err != nil {
@@ -1707,10 +2417,20 @@ func (a *App) ResetAppState() {
 	a.gpxService = gpx.NewService()
 	a.workoutService = workout.NewService()
 	a.currentRouteName = ""
+	a.currentRouteID = 0
+	a.virtualRiders.Clear()
+	a.wind = nil
+	a.ghost = nil
+	a.pacingPlan = nil
+	a.segmentsMu.Lock()
+	a.segments = nil
+	a.segmentsMu.Unlock()
+	a.routeMode = gpx.CourseLoop
 
 	// Resets the physics engine to remove any remaining rotational tilt
 	if profile, err := a.storageService.GetProfile(); err == nil {
 		a.physicsEngine = sim.NewEngine(profile.Weight, profile.BikeWeight)
+		configureEngine(a.physicsEngine, profile)
 	} else {
 		a.physicsEngine = sim.NewEngine(75.0, 9.0)
 	}
@@ -2048,16 +2768,22 @@ func (a *App) SetBuiltInWorkout(testType string) string {
 
 // SaveEventResult is called by the frontend to persist a challenge result.
 func (a *App) SaveEventResult(riderName string, mode string, score float64, status string) error {
+	return a.saveEventRecord(a.fitService, riderName, mode, score, status)
+}
+
+// saveEventRecord writes the FIT file recorded by fitSvc (if long enough) and
+// stores the matching leaderboard entry.
+func (a *App) saveEventRecord(fitSvc *fit.Service, riderName string, mode string, score float64, status string) error {
 	duration := 0
-	if a.fitService != nil {
-		duration = a.fitService.GetRecordCount()
+	if fitSvc != nil {
+		duration = fitSvc.GetRecordCount()
 	}
 
 	filename := ""
 	if duration >= 5 {
 		os.MkdirAll("workouts/events", os.ModePerm)
 		filename = fmt.Sprintf("workouts/events/event_%d.fit", time.Now().UnixNano())
-		if err := a.fitService.Save(filename); err != nil {
+		if err := fitSvc.Save(filename); err != nil {
 			fmt.Printf("Failed to generate FIT file for event: %v\n", err)
 			filename = ""
 		}
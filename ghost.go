@@ -0,0 +1,114 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"argus-cyclist/internal/domain"
+	"argus-cyclist/internal/service/sim"
+)
+
+// ghostLengthTolerance is how far (fraction of the route length) the distance
+// of an activity on an unregistered route may be from the route length.
+const ghostLengthTolerance = 0.05
+
+// onCurrentRoute reports whether an activity was ridden on the current route:
+// the same library route or, for routes outside the library (built-in KOM,
+// generated routes), the same name and length.
+func (a *App) onCurrentRoute(act domain.Activity) bool {
+	if a.currentRouteID != 0 {
+		return act.RouteID == a.currentRouteID
+	}
+	length := a.gpxService.GetTotalDistance()
+	return act.RouteID == 0 && act.RouteName == a.currentRouteName && length > 0 &&
+		math.Abs(act.TotalDistance-length) <= length*ghostLengthTolerance
+}
+
+// GetGhostCandidates lists saved activities recorded on the current route
+// whose FIT file is still available.
+func (a *App) GetGhostCandidates() []domain.Activity {
+	candidates := []domain.Activity{}
+	if a.currentRouteName == "" {
+		return candidates
+	}
+	activities, err := a.storageService.GetAllActivities()
+	if err != nil {
+		return candidates
+	}
+	for _, act := range activities {
+		if !a.onCurrentRoute(act) || act.Filename == "" {
+			continue
+		}
+		if _, err := os.Stat(act.Filename); err != nil {
+			continue
+		}
+		candidates = append(candidates, act)
+	}
+	return candidates
+}
+
+// StartGhostRace loads a past activity on the current route as a ghost.
+// The race starts from the rider's current position and restarts with every session.
+func (a *App) StartGhostRace(activityID uint) error {
+	act, err := a.storageService.GetActivityByID(activityID)
+	if err != nil {
+		return fmt.Errorf("activity not found: %w", err)
+	}
+	if !a.onCurrentRoute(act) {
+		return fmt.Errorf("activity was recorded on %q, not on the current route", act.RouteName)
+	}
+
+	details, err := a.fitService.ParseActivity(act.Filename)
+	if err != nil {
+		return fmt.Errorf("failed to read activity: %w", err)
+	}
+
+	name := fmt.Sprintf("%s (%s)", act.RouteName, act.CreatedAt.Format("02/01/2006"))
+	ghost, err := sim.NewGhost(act.ID, name, details.Elapsed, details.Distance)
+	if err != nil {
+		return err
+	}
+	ghost.Start(a.sessionActiveTime, a.currentDist)
+	a.ghost = ghost
+
+	fmt.Printf("[GHOST] Racing activity %d (%.2f km)\n", act.ID, ghost.TotalDistance()/1000.0)
+	return nil
+}
+
+// StopGhostRace removes the ghost.
+func (a *App) StopGhostRace() {
+	a.ghost = nil
+}
+
+// GetGhostSummary returns where time was gained or lost against the ghost so far.
+func (a *App) GetGhostSummary() (sim.GhostSummary, error) {
+	if a.ghost == nil {
+		return sim.GhostSummary{}, fmt.Errorf("no ghost race active")
+	}
+	return a.ghost.Summary(), nil
+}
+
+// ghostState updates the ghost and places it on the route.
+func (a *App) ghostState(totalRouteDistance float64) sim.GhostState {
+	state := a.ghost.Update(a.sessionActiveTime, a.currentDist)
+	p, _ := a.coursePoint(state.Distance, totalRouteDistance)
+	state.Latitude, state.Longitude = p.Latitude, p.Longitude
+	return state
+}
@@ -45,6 +45,9 @@ type TrainerService interface {
 	// SetTrainerMode switches between "SIM" and "ERG"
 	SetTrainerMode(mode string)
 
+	// SetSimulationParams sends the aerodynamic simulation parameters (SIM mode)
+	SetSimulationParams(params SimulationParams) error
+
 	// Disconnect disconnects everything
 	Disconnect()
 }
@@ -59,6 +62,8 @@ type GPXService interface {
 type FitService interface {
 	StartSession(startTime time.Time)
 	AddRecord(t Telemetry)
+	AddLap(lap LapStats)
+	AddCoursePointEvent(index int, at time.Time)
 	Save(filepath string) error
 }
 
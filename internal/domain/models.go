@@ -26,6 +26,36 @@ type RoutePoint struct {
 	Elevation float64 `json:"elevation"` // Elevation in meters
 	Distance  float64 `json:"distance"`  // Accumulated distance from the start (in meters)
 	Grade     float64 `json:"grade"`     // Grade in % (e.g., 5.2 for 5.2%)
+	Surface   string  `json:"surface"`   // Road surface from this point on (Surface* constants)
+}
+
+// Road surfaces. An empty surface is treated as asphalt.
+const (
+	SurfaceAsphalt  = "asphalt"
+	SurfaceConcrete = "concrete"
+	SurfaceGravel   = "gravel"
+	SurfaceDirt     = "dirt"
+	SurfaceCobbles  = "cobbles"
+	SurfaceGrass    = "grass"
+)
+
+// LapStats summarises one lap of a route (or the final partial lap of a session).
+type LapStats struct {
+	Index         int       `json:"index"` // 1-based
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Duration      float64   `json:"duration"`   // Active time (s)
+	SplitTime     float64   `json:"split_time"` // Active session time at the end of the lap (s)
+	Distance      float64   `json:"distance"`   // m
+	AvgPower      int       `json:"avg_power"`
+	MaxPower      int       `json:"max_power"`
+	AvgHR         int       `json:"avg_hr"`
+	MaxHR         int       `json:"max_hr"`
+	AvgCadence    int       `json:"avg_cadence"`
+	AvgSpeed      float64   `json:"avg_speed"` // km/h
+	MaxSpeed      float64   `json:"max_speed"` // km/h
+	ElevationGain float64   `json:"elevation_gain"`
+	Complete      bool      `json:"complete"` // false for the partial lap closed when the session ends
 }
 
 // Telemetry represents the real-time cyclist and trainer state.
@@ -33,18 +63,41 @@ type RoutePoint struct {
 // - The Frontend (Wails)
 // - The .FIT activity file
 type Telemetry struct {
-	Timestamp     time.Time `json:"timestamp"`      // Time of the telemetry sample
-	Power         int16     `json:"power"`          // Power output in watts
-	Cadence       uint8     `json:"cadence"`        // Cadence in RPM
-	HeartRate     uint8     `json:"heart_rate"`     // Heart rate in BPM
-	Speed         float64   `json:"speed"`          // Virtual speed in km/h
-	TotalDistance float64   `json:"total_dist"`     // Total distance traveled (meters)
-	CurrentGrade  float64   `json:"grade"`          // Current Grade (%)
-	Latitude      float64   `json:"lat"`            // Current latitude
-	Longitude     float64   `json:"lon"`            // Current longitude
-	Altitude      float64   `json:"alt"`            //Current altitude in meters
-	ElevationGain float64   `json:"elevation_gain"` //Cumulative elevation gain
-	RiderWeight   float64   `json:"rider_weight"`   // Rider weight in kg
+	Timestamp        time.Time `json:"timestamp"`         // Time of the telemetry sample
+	Power            int16     `json:"power"`             // Power output in watts
+	Cadence          uint8     `json:"cadence"`           // Cadence in RPM
+	HeartRate        uint8     `json:"heart_rate"`        // Heart rate in BPM
+	Speed            float64   `json:"speed"`             // Virtual speed in km/h
+	TotalDistance    float64   `json:"total_dist"`        // Total distance traveled (meters)
+	CurrentGrade     float64   `json:"grade"`             // Current Grade (%)
+	Latitude         float64   `json:"lat"`               // Current latitude
+	Longitude        float64   `json:"lon"`               // Current longitude
+	Altitude         float64   `json:"alt"`               //Current altitude in meters
+	ElevationGain    float64   `json:"elevation_gain"`    //Cumulative elevation gain
+	RiderWeight      float64   `json:"rider_weight"`      // Rider weight in kg
+	DraftSaving      float64   `json:"draft_saving"`      // Drag reduction from drafting (0-1)
+	Headwind         float64   `json:"headwind"`          // Wind against the rider (m/s, negative = tailwind)
+	Crosswind        float64   `json:"crosswind"`         // Wind across the rider (m/s, positive = from the right)
+	Surface          string    `json:"surface"`           // Road surface under the rider
+	ClimbIndex       int       `json:"climb_index"`       // Climb being ridden (1-based, 0 = not climbing)
+	ClimbCategory    string    `json:"climb_category"`    // "4", "3", "2", "1" or "HC"
+	ClimbRemaining   float64   `json:"climb_remaining"`   // Distance left to the top (m)
+	Segment          string    `json:"segment"`           // Segment being timed ("" = none)
+	SegmentElapsed   float64   `json:"segment_elapsed"`   // s since the segment start
+	SegmentRemaining float64   `json:"segment_remaining"` // m to the segment end
+	SegmentPRGap     float64   `json:"segment_pr_gap"`    // s behind (+) or ahead (-) of the PR pace, 0 without a PR
+	PlanPower        int       `json:"plan_power"`        // Pacing plan target (W, 0 = no plan)
+	VideoTime        float64   `json:"video_time"`        // Route video position (s, route with a video only)
+	VideoRate        float64   `json:"video_rate"`        // Route video playback rate (0 = paused)
+}
+
+// SimulationParams are the SIM mode parameters besides grade that the trainer
+// uses to compute resistance (FE-C page 50 / FTMS indoor bike simulation).
+type SimulationParams struct {
+	WindResistance float64 `json:"wind_resistance"` // CdA × air density (kg/m)
+	DraftingFactor float64 `json:"drafting_factor"` // 1.0 = no draft, 0.0 = no air resistance
+	WindSpeed      float64 `json:"wind_speed"`      // Headwind component (m/s, negative = tailwind)
+	Crr            float64 `json:"crr"`             // Rolling resistance coefficient (0 = trainer default)
 }
 
 // AppState represents the global state of the application.
@@ -74,6 +127,19 @@ type UserProfile struct {
 	LTHR       int     `json:"lthr"`       // Lactate Threshold Heart Rate (bpm)
 	RestingHR  int     `json:"resting_hr"` // Resting Heart Rate for TRIMP
 
+	// Physics model (0 = use the preset for BikeType)
+	BikeType       string   `json:"bike_type"`       // "road_hoods", "road_drops", "tt", "gravel", "mtb"
+	CdA            float64  `json:"cda"`             // Drag area (m²)
+	Crr            float64  `json:"crr"`             // Rolling resistance coefficient
+	Drivetrain     float64  `json:"drivetrain"`      // Drivetrain efficiency (0-1)
+	AirTemperature *float64 `json:"air_temperature"` // °C (null = standard 15 °C; 0 °C and below are valid)
+	AirPressure    float64  `json:"air_pressure"`    // Sea-level pressure (hPa)
+	SpeedModel     string   `json:"speed_model"`     // "momentum" (default) or "steady"
+
+	// Elevation correction from local SRTM .hgt tiles
+	DEMDirectory  string `json:"dem_directory"`
+	DEMCorrection bool   `json:"dem_correction"` // Correct every route on load
+
 	Level      int   `json:"level"`
 	CurrentXP  int64 `json:"current_xp"`
 	TotalCoins int   `json:"total_coins"`
@@ -137,6 +203,52 @@ type Activity struct {
 	PeakHR            int            `json:"peak_hr"`
 	HRR1              int            `json:"hrr_1"`
 	HRR2              int            `json:"hrr_2"`
+	RouteID           uint           `json:"route_id"` // Library route ridden (0 = none)
+}
+
+// Route is an entry of the route library, with the stats of its rides.
+type Route struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	Name          string       `json:"name"`
+	Source        string       `json:"source" gorm:"uniqueIndex"`        // Route file path
+	Distance      float64      `json:"distance"`                         // m
+	ElevationGain float64      `json:"elevation_gain"`                   // m
+	Climbs        int          `json:"climbs"`                           // Categorised climbs
+	Mode          string       `json:"mode"`                             // Route end behaviour ("loop", "finish", "out_and_back"; "" = loop)
+	Thumbnail     [][2]float64 `json:"thumbnail" gorm:"serializer:json"` // Simplified [lat, lon] polyline
+	TimesRidden   int          `json:"times_ridden"`
+	LastRidden    *time.Time   `json:"last_ridden"`
+	BestTime      float64      `json:"best_time"` // Fastest full lap (s, 0 = never completed)
+	BestDate      *time.Time   `json:"best_date"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// Segment is a user-defined timed section of a route. Segments are kept in
+// the master DB so every profile times (and ranks on) the same definitions.
+type Segment struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	RouteSource string    `json:"route_source" gorm:"index"` // Absolute path of the route file (as in Route.Source)
+	RouteName   string    `json:"route_name"`                // Route file name (as in Activity.RouteName)
+	Name        string    `json:"name"`
+	Start       float64   `json:"start"` // m along the route
+	End         float64   `json:"end"`   // m along the route
+	StartLat    float64   `json:"start_lat"`
+	StartLon    float64   `json:"start_lon"`
+	EndLat      float64   `json:"end_lat"`
+	EndLon      float64   `json:"end_lon"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SegmentEffort is one timed ride across a segment.
+type SegmentEffort struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	SegmentID uint      `json:"segment_id" gorm:"index"`
+	Time      float64   `json:"time"` // s
+	AvgPower  int       `json:"avg_power"`
+	Wkg       float64   `json:"wkg"`
+	AvgHR     int       `json:"avg_hr"`
+	Date      time.Time `json:"date"`
 }
 
 // EventRecord represents a leaderboard entry for Event Mode.
@@ -215,4 +327,25 @@ const (
 type BLEDevice struct {
 	Name    string `json:"name"`
 	Address string `json:"address"` //MAC address
+}
+
+// Device types reported by the continuous BLE discovery.
+const (
+	DeviceFTMSTrainer = "FTMS_TRAINER"
+	DeviceFECTrainer  = "FEC_TRAINER"
+	DevicePowerMeter  = "POWER_METER"
+	DeviceCSC         = "CSC"
+	DeviceHRM         = "HRM"
+	DeviceUnknown     = "UNKNOWN"
+)
+
+// DiscoveredDevice is emitted incrementally while a discovery scan is running.
+// The same address may be reported again when its RSSI or advertisement changes.
+type DiscoveredDevice struct {
+	Name         string    `json:"name"`
+	Address      string    `json:"address"`       // MAC address
+	RSSI         int16     `json:"rssi"`          // Signal strength (dBm)
+	ServiceUUIDs []string  `json:"service_uuids"` // Advertised services seen so far
+	Type         string    `json:"type"`          // One of the Device* constants
+	LastSeen     time.Time `json:"last_seen"`
 }
\ No newline at end of file
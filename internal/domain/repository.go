@@ -37,6 +37,18 @@ type ProfileSummary struct {
 	TotalElevation float64 `json:"total_elevation"`
 }
 
+// SegmentLeaderboardEntry is a profile's best effort on a segment.
+type SegmentLeaderboardEntry struct {
+	ProfileID string    `json:"profile_id"`
+	Name      string    `json:"name"`
+	Avatar    string    `json:"avatar"`
+	Time      float64   `json:"time"`
+	AvgPower  int       `json:"avg_power"`
+	Wkg       float64   `json:"wkg"`
+	AvgHR     int       `json:"avg_hr"`
+	Date      time.Time `json:"date"`
+}
+
 // PowerRecord represents a power curve data point.
 type PowerRecord struct {
 	Duration int       `json:"duration" gorm:"primaryKey"`
@@ -50,8 +62,11 @@ type ConnectionManager interface {
 	LoadUserDatabase(userID string) error
 	GetProfilesSummary() []ProfileSummary
 	GetLocalAccounts() []LocalAccount
+	GetAccountProfile(id string) (UserProfile, error)
 	CreateLocalAccount(acc LocalAccount) error
 	DeleteLocalAccount(id string) error
+	GetSegmentLeaderboard(segmentID uint, limit int) []SegmentLeaderboardEntry
+	DeleteSegmentEfforts(segmentID uint) error
 }
 
 // UserRepository handles the current user's profile operations.
@@ -83,6 +98,31 @@ type ActivityRepository interface {
 	UpdateActivityStatus(id uint, uploaded bool) error
 }
 
+// RouteRepository manages the route library.
+type RouteRepository interface {
+	SaveRoute(r *Route) error
+	GetRoutes() ([]Route, error)
+	SearchRoutes(query string) ([]Route, error)
+	GetRouteByID(id uint) (Route, error)
+	GetRouteBySource(source string) (Route, error)
+	RenameRoute(id uint, name string) error
+	SetRouteMode(id uint, mode string) error
+	DeleteRoute(id uint) error
+	GetRouteActivities(id uint) ([]Activity, error)
+	RecordRouteRide(id uint, when time.Time, lapTime float64) error
+}
+
+// SegmentRepository manages timed segments and their efforts.
+type SegmentRepository interface {
+	SaveSegment(seg *Segment) error
+	GetSegmentsForRoute(routeSource string) ([]Segment, error)
+	GetSegmentByID(id uint) (Segment, error)
+	DeleteSegment(id uint) error
+	SaveSegmentEffort(e *SegmentEffort) error
+	GetSegmentEfforts(segmentID uint) ([]SegmentEffort, error)
+	GetBestSegmentEffort(segmentID uint) (SegmentEffort, error)
+}
+
 // PowerRepository manages power curve records.
 type PowerRepository interface {
 	GetPowerCurve() []PowerRecord
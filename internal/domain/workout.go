@@ -22,11 +22,14 @@ import (
 
 // Structures for XML PARSING (ZWO Format)
 type ZWOFile struct {
-	XMLName     xml.Name   `xml:"workout_file" json:"-"` // Ignora no JSON
-	Name        string     `xml:"name" json:"name"`
-	Description string     `xml:"description" json:"description"`
-	Author      string     `xml:"author" json:"author"`
-	Workout     ZWOWorkout `xml:"workout" json:"workout"`
+	XMLName      xml.Name   `xml:"workout_file" json:"-"` // Ignora no JSON
+	Name         string     `xml:"name" json:"name"`
+	Description  string     `xml:"description" json:"description"`
+	Author       string     `xml:"author" json:"author"`
+	SportType    string     `xml:"sportType" json:"sport_type,omitempty"`
+	DurationType string     `xml:"durationType" json:"duration_type,omitempty"` // "time" (default) or "distance"
+	FTPOverride  int        `xml:"ftpOverride" json:"ftp_override,omitempty"`   // FTP (W) the workout is written for
+	Workout      ZWOWorkout `xml:"workout" json:"workout"`
 }
 
 type ZWOWorkout struct {
@@ -35,17 +38,34 @@ type ZWOWorkout struct {
 }
 
 type ZWOStep struct {
-	XMLName     xml.Name `json:"-"`
-	Duration    int      `xml:"Duration,attr" json:"duration,omitempty"`
-	Power       float64  `xml:"Power,attr" json:"power,omitempty"`
-	PowerLow    float64  `xml:"PowerLow,attr" json:"power_low,omitempty"`
-	PowerHigh   float64  `xml:"PowerHigh,attr" json:"power_high,omitempty"`
-	Repeat      int      `xml:"Repeat,attr" json:"repeat,omitempty"`
-	OnDuration  int      `xml:"OnDuration,attr" json:"on_duration,omitempty"`
-	OnPower     float64  `xml:"OnPower,attr" json:"on_power,omitempty"`
-	OffDuration int      `xml:"OffDuration,attr" json:"off_duration,omitempty"`
-	OffPower    float64  `xml:"OffPower,attr" json:"off_power,omitempty"`
-	Cadence     int      `xml:"Cadence,attr" json:"cadence,omitempty"`
+	XMLName        xml.Name       `json:"-"`
+	Duration       float64        `xml:"Duration,attr" json:"duration,omitempty"`
+	Power          float64        `xml:"Power,attr" json:"power,omitempty"`
+	PowerLow       float64        `xml:"PowerLow,attr" json:"power_low,omitempty"`
+	PowerHigh      float64        `xml:"PowerHigh,attr" json:"power_high,omitempty"`
+	Repeat         int            `xml:"Repeat,attr" json:"repeat,omitempty"`
+	OnDuration     float64        `xml:"OnDuration,attr" json:"on_duration,omitempty"`
+	OnPower        float64        `xml:"OnPower,attr" json:"on_power,omitempty"`
+	PowerOnLow     float64        `xml:"PowerOnLow,attr" json:"power_on_low,omitempty"`
+	PowerOnHigh    float64        `xml:"PowerOnHigh,attr" json:"power_on_high,omitempty"`
+	OffDuration    float64        `xml:"OffDuration,attr" json:"off_duration,omitempty"`
+	OffPower       float64        `xml:"OffPower,attr" json:"off_power,omitempty"`
+	PowerOffLow    float64        `xml:"PowerOffLow,attr" json:"power_off_low,omitempty"`
+	PowerOffHigh   float64        `xml:"PowerOffHigh,attr" json:"power_off_high,omitempty"`
+	Cadence        int            `xml:"Cadence,attr" json:"cadence,omitempty"`
+	CadenceLow     int            `xml:"CadenceLow,attr" json:"cadence_low,omitempty"`
+	CadenceHigh    int            `xml:"CadenceHigh,attr" json:"cadence_high,omitempty"`
+	CadenceResting int            `xml:"CadenceResting,attr" json:"cadence_resting,omitempty"` // IntervalsT off cadence
+	FlatRoad       *int           `xml:"FlatRoad,attr" json:"flat_road,omitempty"` // nil = not given
+	TextEvents     []ZWOTextEvent `xml:"textevent" json:"text_events,omitempty"`
+}
+
+// ZWOTextEvent is an on-screen message, timed from the start of its step.
+type ZWOTextEvent struct {
+	TimeOffset float64 `xml:"timeoffset,attr" json:"time_offset"`
+	DistOffset float64 `xml:"distoffset,attr" json:"dist_offset,omitempty"`
+	Message    string  `xml:"message,attr" json:"message"`
+	Duration   float64 `xml:"duration,attr" json:"duration,omitempty"`
 }
 
 // ===================================
@@ -53,21 +73,33 @@ type ZWOStep struct {
 // ===================================
 
 type WorkoutSegment struct {
-	Index           int     `json:"index"`
-	Type            string  `json:"type"`
-	DurationSeconds int     `json:"duration"`
-	StartFactor     float64 `json:"start_factor"`
-	EndFactor       float64 `json:"end_factor"`
-	Text            string  `json:"text"`
-	FreeRide        bool    `json:"free_ride"`
+	Index           int                `json:"index"`
+	Type            string             `json:"type"`
+	DurationSeconds int                `json:"duration"`
+	StartFactor     float64            `json:"start_factor"`
+	EndFactor       float64            `json:"end_factor"`
+	Text            string             `json:"text"`
+	FreeRide        bool               `json:"free_ride"`
+	CadenceLow      int                `json:"cadence_low,omitempty"`  // Target cadence range (rpm, 0 = none)
+	CadenceHigh     int                `json:"cadence_high,omitempty"` // Equal to CadenceLow for a fixed cadence
+	TextEvents      []WorkoutTextEvent `json:"text_events,omitempty"`
+}
+
+// WorkoutTextEvent is an on-screen message shown during a segment.
+type WorkoutTextEvent struct {
+	Offset   int    `json:"offset"` // s from the segment start
+	Message  string `json:"message"`
+	Duration int    `json:"duration"` // s on screen (0 = frontend default)
 }
 
 type ActiveWorkout struct {
 	Metadata      ZWOFile          `json:"metadata"`
 	Segments      []WorkoutSegment `json:"segments"`
 	TotalDuration int              `json:"total_duration"`
-	IsTest        bool             `json:"is_test"`   // Indicates whether the session is an aptitude test.
-	TestType      string           `json:"test_type"` // "ramp", "ftp20", "vo2max5"
+	IsTest        bool             `json:"is_test"`      // Indicates whether the session is an aptitude test.
+	TestType      string           `json:"test_type"`    // "ramp", "ftp20", "vo2max5"
+	FTPOverride   int              `json:"ftp_override"` // FTP (W) targets are computed from instead of the rider's (0 = rider's)
+	Warnings      []string         `json:"warnings"`     // Parts of the file that could not be followed exactly
 }
 
 // Structure for sending state to the Frontend
@@ -82,4 +114,6 @@ type WorkoutState struct {
 	CompletionPercent float64 `json:"completion_percent"`
 	IntensityPct      int     `json:"intensity_pct"`
 	IsFreeRide        bool    `json:"is_free_ride"`
-}
\ No newline at end of file
+	CadenceLow        int     `json:"cadence_low"` // Target cadence range (rpm, 0 = none)
+	CadenceHigh       int     `json:"cadence_high"`
+}
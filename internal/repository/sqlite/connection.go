@@ -20,6 +20,7 @@ import (
 	"argus-cyclist/internal/domain"
 	"fmt"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/glebarez/sqlite"
@@ -44,7 +45,7 @@ func NewDBState() *DBState {
 		panic("failed to connect to master database")
 	}
 
-	err = db.AutoMigrate(&domain.LocalAccount{})
+	err = db.AutoMigrate(&domain.LocalAccount{}, &domain.Segment{})
 	if err != nil {
 		fmt.Println("Error migrating master DB:", err)
 	}
@@ -78,7 +79,7 @@ func (s *ConnectionManager) LoadUserDatabase(userID string) error {
 		return fmt.Errorf("Failed to open SQLite database: %v", err)
 	}
 
-	err = db.AutoMigrate(&domain.UserProfile{}, &domain.Activity{}, &domain.PowerRecord{}, &domain.SyncQueue{}, &domain.UserBadge{}, &domain.CustomGoal{}, &domain.BikeComponent{}, &domain.ComponentReplacement{}, &domain.AIConversation{}, &domain.AIMessage{})
+	err = db.AutoMigrate(&domain.UserProfile{}, &domain.Activity{}, &domain.PowerRecord{}, &domain.SyncQueue{}, &domain.UserBadge{}, &domain.CustomGoal{}, &domain.BikeComponent{}, &domain.ComponentReplacement{}, &domain.AIConversation{}, &domain.AIMessage{}, &domain.Route{}, &domain.SegmentEffort{})
 	if err != nil {
 		return fmt.Errorf("Table migration failed: %v", err)
 	}
@@ -164,6 +165,28 @@ func (s *ConnectionManager) GetLocalAccounts() []domain.LocalAccount {
 	return accounts
 }
 
+// GetAccountProfile reads the profile stored in another account's isolated DB
+// without switching the active user database.
+func (s *ConnectionManager) GetAccountProfile(id string) (domain.UserProfile, error) {
+	var user domain.UserProfile
+
+	dbPath := fmt.Sprintf("users_data/argus_data_%s.db", id)
+	if _, err := os.Stat(dbPath); err != nil {
+		return user, fmt.Errorf("profile database not found: %v", err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		return user, fmt.Errorf("Failed to open SQLite database: %v", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	err = db.First(&user).Error
+	return user, err
+}
+
 func (s *ConnectionManager) CreateLocalAccount(acc domain.LocalAccount) error {
 	return s.state.MasterDB.Create(&acc).Error
 }
@@ -179,3 +202,77 @@ func (s *ConnectionManager) DeleteLocalAccount(id string) error {
 	}
 	return nil
 }
+
+// GetSegmentLeaderboard collects every profile's best effort on the segment,
+// reading each isolated DB like GetProfilesSummary.
+func (s *ConnectionManager) GetSegmentLeaderboard(segmentID uint, limit int) []domain.SegmentLeaderboardEntry {
+	var accounts []domain.LocalAccount
+	s.state.MasterDB.Find(&accounts)
+
+	var entries []domain.SegmentLeaderboardEntry
+	for _, acc := range accounts {
+		dbPath := fmt.Sprintf("users_data/argus_data_%s.db", acc.ID)
+		if _, err := os.Stat(dbPath); err != nil {
+			continue
+		}
+		db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+		if err != nil {
+			continue
+		}
+
+		var best domain.SegmentEffort
+		if db.Migrator().HasTable(&domain.SegmentEffort{}) {
+			db.Where("segment_id = ?", segmentID).Order("time asc").Limit(1).Find(&best)
+		}
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		if best.ID == 0 {
+			continue
+		}
+
+		entries = append(entries, domain.SegmentLeaderboardEntry{
+			ProfileID: acc.ID,
+			Name:      acc.Name,
+			Avatar:    acc.Avatar,
+			Time:      best.Time,
+			AvgPower:  best.AvgPower,
+			Wkg:       best.Wkg,
+			AvgHR:     best.AvgHR,
+			Date:      best.Date,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Time < entries[j].Time })
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// DeleteSegmentEfforts removes the efforts on a deleted segment from every profile's DB.
+func (s *ConnectionManager) DeleteSegmentEfforts(segmentID uint) error {
+	var accounts []domain.LocalAccount
+	s.state.MasterDB.Find(&accounts)
+
+	for _, acc := range accounts {
+		dbPath := fmt.Sprintf("users_data/argus_data_%s.db", acc.ID)
+		if _, err := os.Stat(dbPath); err != nil {
+			continue
+		}
+		db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", dbPath, err)
+		}
+		if db.Migrator().HasTable(&domain.SegmentEffort{}) {
+			err = db.Where("segment_id = ?", segmentID).Delete(&domain.SegmentEffort{}).Error
+		}
+		if sqlDB, closeErr := db.DB(); closeErr == nil {
+			sqlDB.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete segment efforts for %s: %v", acc.Name, err)
+		}
+	}
+	return nil
+}
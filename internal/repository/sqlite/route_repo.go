@@ -0,0 +1,140 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sqlite
+
+import (
+	"argus-cyclist/internal/domain"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RouteRepo struct {
+	state *DBState
+}
+
+func NewRouteRepository(state *DBState) domain.RouteRepository {
+	return &RouteRepo{state: state}
+}
+
+// SaveRoute creates the route or updates it when it already has an ID.
+func (r *RouteRepo) SaveRoute(route *domain.Route) error {
+	if r.state.UserDB == nil {
+		return fmt.Errorf("no user loaded")
+	}
+	return r.state.UserDB.Save(route).Error
+}
+
+func (r *RouteRepo) GetRoutes() ([]domain.Route, error) {
+	var routes []domain.Route
+	if r.state.UserDB == nil {
+		return routes, nil
+	}
+	err := r.state.UserDB.Order("last_ridden desc, created_at desc").Find(&routes).Error
+	return routes, err
+}
+
+func (r *RouteRepo) SearchRoutes(query string) ([]domain.Route, error) {
+	var routes []domain.Route
+	if r.state.UserDB == nil {
+		return routes, nil
+	}
+	like := "%" + query + "%"
+	err := r.state.UserDB.Where("name LIKE ? OR source LIKE ?", like, like).Order("name asc").Find(&routes).Error
+	return routes, err
+}
+
+func (r *RouteRepo) GetRouteByID(id uint) (domain.Route, error) {
+	var route domain.Route
+	if r.state.UserDB == nil {
+		return route, fmt.Errorf("no db")
+	}
+	err := r.state.UserDB.First(&route, id).Error
+	return route, err
+}
+
+func (r *RouteRepo) GetRouteBySource(source string) (domain.Route, error) {
+	var route domain.Route
+	if r.state.UserDB == nil {
+		return route, fmt.Errorf("no db")
+	}
+	// Find instead of First: a new file is the normal case, not an error worth logging
+	if err := r.state.UserDB.Where("source = ?", source).Limit(1).Find(&route).Error; err != nil {
+		return route, err
+	}
+	if route.ID == 0 {
+		return route, fmt.Errorf("route not in the library: %s", source)
+	}
+	return route, nil
+}
+
+func (r *RouteRepo) RenameRoute(id uint, name string) error {
+	if r.state.UserDB == nil {
+		return fmt.Errorf("no user loaded")
+	}
+	return r.state.UserDB.Model(&domain.Route{}).Where("id = ?", id).Update("name", name).Error
+}
+
+// SetRouteMode stores what happens at the end of the route.
+func (r *RouteRepo) SetRouteMode(id uint, mode string) error {
+	if r.state.UserDB == nil {
+		return fmt.Errorf("no user loaded")
+	}
+	return r.state.UserDB.Model(&domain.Route{}).Where("id = ?", id).Update("mode", mode).Error
+}
+
+// DeleteRoute removes the route from the library; its activities keep their history.
+func (r *RouteRepo) DeleteRoute(id uint) error {
+	if r.state.UserDB == nil {
+		return fmt.Errorf("no db")
+	}
+	return r.state.UserDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Activity{}).Where("route_id = ?", id).Update("route_id", 0).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&domain.Route{}, id).Error
+	})
+}
+
+// GetRouteActivities returns the activities ridden on the route, oldest first.
+func (r *RouteRepo) GetRouteActivities(id uint) ([]domain.Activity, error) {
+	var activities []domain.Activity
+	if r.state.UserDB == nil {
+		return activities, nil
+	}
+	err := r.state.UserDB.Where("route_id = ?", id).Order("created_at asc").Find(&activities).Error
+	return activities, err
+}
+
+// RecordRouteRide counts a ride of the route and keeps the fastest full lap (lapTime 0 = not completed).
+func (r *RouteRepo) RecordRouteRide(id uint, when time.Time, lapTime float64) error {
+	if r.state.UserDB == nil {
+		return fmt.Errorf("no user loaded")
+	}
+	route, err := r.GetRouteByID(id)
+	if err != nil {
+		return err
+	}
+	route.TimesRidden++
+	route.LastRidden = &when
+	if lapTime > 0 && (route.BestTime == 0 || lapTime < route.BestTime) {
+		route.BestTime = lapTime
+		route.BestDate = &when
+	}
+	return r.state.UserDB.Save(&route).Error
+}
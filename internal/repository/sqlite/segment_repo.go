@@ -0,0 +1,82 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sqlite
+
+import (
+	"argus-cyclist/internal/domain"
+	"fmt"
+)
+
+type SegmentRepo struct {
+	state *DBState
+}
+
+func NewSegmentRepository(state *DBState) domain.SegmentRepository {
+	return &SegmentRepo{state: state}
+}
+
+// Segment definitions live in the master DB (shared by every profile);
+// efforts live in each profile's own DB.
+
+func (r *SegmentRepo) SaveSegment(seg *domain.Segment) error {
+	return r.state.MasterDB.Save(seg).Error
+}
+
+func (r *SegmentRepo) GetSegmentsForRoute(routeSource string) ([]domain.Segment, error) {
+	var segments []domain.Segment
+	err := r.state.MasterDB.Where("route_source = ?", routeSource).Order("start asc").Find(&segments).Error
+	return segments, err
+}
+
+func (r *SegmentRepo) GetSegmentByID(id uint) (domain.Segment, error) {
+	var seg domain.Segment
+	err := r.state.MasterDB.First(&seg, id).Error
+	return seg, err
+}
+
+// DeleteSegment removes the definition; the efforts are removed per profile
+// with ConnectionManager.DeleteSegmentEfforts.
+func (r *SegmentRepo) DeleteSegment(id uint) error {
+	return r.state.MasterDB.Delete(&domain.Segment{}, id).Error
+}
+
+func (r *SegmentRepo) SaveSegmentEffort(e *domain.SegmentEffort) error {
+	if r.state.UserDB == nil {
+		return fmt.Errorf("no user loaded")
+	}
+	return r.state.UserDB.Create(e).Error
+}
+
+// GetSegmentEfforts returns the segment's effort history, oldest first.
+func (r *SegmentRepo) GetSegmentEfforts(segmentID uint) ([]domain.SegmentEffort, error) {
+	var efforts []domain.SegmentEffort
+	if r.state.UserDB == nil {
+		return efforts, nil
+	}
+	err := r.state.UserDB.Where("segment_id = ?", segmentID).Order("date asc").Find(&efforts).Error
+	return efforts, err
+}
+
+// GetBestSegmentEffort returns the fastest effort (ID 0 if the segment was never completed).
+func (r *SegmentRepo) GetBestSegmentEffort(segmentID uint) (domain.SegmentEffort, error) {
+	var best domain.SegmentEffort
+	if r.state.UserDB == nil {
+		return best, fmt.Errorf("no db")
+	}
+	err := r.state.UserDB.Where("segment_id = ?", segmentID).Order("time asc").Limit(1).Find(&best).Error
+	return best, err
+}
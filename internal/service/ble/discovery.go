@@ -0,0 +1,236 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package ble
+
+import (
+	"argus-cyclist/internal/domain"
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"tinygo.org/x/bluetooth"
+)
+
+var ServiceCSC = bluetooth.ServiceUUIDCyclingSpeedAndCadence
+
+const (
+	// A device already reported is only re-emitted after this interval...
+	discoveryReportInterval = 1 * time.Second
+	// ...and only if its RSSI moved by at least this many dBm (or its type/name changed).
+	discoveryRSSIDelta = 3
+)
+
+// discoveryEntry accumulates what we know about one address.
+// Advertisements and scan responses carry different fields, so names and
+// service UUIDs are merged across packets instead of being overwritten.
+type discoveryEntry struct {
+	device      domain.DiscoveredDevice
+	uuids       map[bluetooth.UUID]bool
+	lastEmit    time.Time
+	lastEmitted domain.DiscoveredDevice // Snapshot of the last report, changes are measured against it
+}
+
+// classifyDevice picks the most specific role from the advertised services.
+// FE-C is checked before FTMS because many trainers advertise both and
+// SubscribeStats prefers the FE-C channel when it is available.
+func classifyDevice(uuids map[bluetooth.UUID]bool) string {
+	switch {
+	case uuids[ServiceFEC] || uuids[ServiceFEC128]:
+		return domain.DeviceFECTrainer
+	case uuids[ServiceFitnessMach]:
+		return domain.DeviceFTMSTrainer
+	case uuids[ServiceCyclingPower]:
+		return domain.DevicePowerMeter
+	case uuids[ServiceCSC]:
+		return domain.DeviceCSC
+	case uuids[ServiceHeartRate]:
+		return domain.DeviceHRM
+	}
+	return domain.DeviceUnknown
+}
+
+// IsTrainerDevice reports whether a discovered device can be used as the trainer.
+func IsTrainerDevice(d domain.DiscoveredDevice) bool {
+	switch d.Type {
+	case domain.DeviceFTMSTrainer, domain.DeviceFECTrainer, domain.DevicePowerMeter:
+		return true
+	}
+	return false
+}
+
+// IsHRDevice reports whether a discovered device exposes the Heart Rate service,
+// even if it was classified under a more specific role (e.g. a trainer with HR).
+func IsHRDevice(d domain.DiscoveredDevice) bool {
+	if d.Type == domain.DeviceHRM {
+		return true
+	}
+	hr := ServiceHeartRate.String()
+	for _, u := range d.ServiceUUIDs {
+		if u == hr {
+			return true
+		}
+	}
+	return false
+}
+
+// Discover runs a single scan covering every sensor role until ctx is cancelled.
+// onDevice is called the first time an address is seen and again (throttled)
+// whenever its RSSI, name or classification changes.
+func (s *RealService) Discover(ctx context.Context, onDevice func(domain.DiscoveredDevice)) error {
+	if err := s.enableAdapter(); err != nil {
+		return fmt.Errorf("Bluetooth error: %w", err)
+	}
+
+	entries := make(map[string]*discoveryEntry)
+	var mu sync.Mutex
+	done := make(chan error, 1)
+
+	fmt.Println("[BLE] Starting continuous discovery...")
+
+	go func() {
+		done <- s.adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
+			if bleDebugEnabled() {
+				fmt.Printf("[BLE][SCAN][%s][DISCOVERY] addr=%s name=%q rssi=%d uuids=%v\n",
+					runtime.GOOS,
+					result.Address.String(),
+					result.LocalName(),
+					result.RSSI,
+					result.ServiceUUIDs(),
+				)
+			}
+
+			mac := result.Address.String()
+			now := time.Now()
+
+			mu.Lock()
+			entry, known := entries[mac]
+			if !known {
+				entry = &discoveryEntry{
+					device: domain.DiscoveredDevice{Address: mac},
+					uuids:  make(map[bluetooth.UUID]bool),
+				}
+				entries[mac] = entry
+			}
+
+			for _, u := range result.ServiceUUIDs() {
+				entry.uuids[u] = true
+			}
+			// Some backends never populate ServiceUUIDs(); probe the ones we care about.
+			for _, u := range []bluetooth.UUID{ServiceFEC, ServiceFEC128, ServiceFitnessMach, ServiceCyclingPower, ServiceCSC, ServiceHeartRate} {
+				if !entry.uuids[u] && result.HasServiceUUID(u) {
+					entry.uuids[u] = true
+				}
+			}
+			if n := result.LocalName(); n != "" {
+				entry.device.Name = n
+			} else if entry.device.Name == "" {
+				entry.device.Name = scanDisplayName(result)
+			}
+			entry.device.RSSI = result.RSSI
+			entry.device.Type = classifyDevice(entry.uuids)
+			entry.device.LastSeen = now
+
+			prev := entry.lastEmitted
+			rssiDelta := int(entry.device.RSSI) - int(prev.RSSI)
+			if rssiDelta < 0 {
+				rssiDelta = -rssiDelta
+			}
+			changed := entry.device.Type != prev.Type || entry.device.Name != prev.Name || rssiDelta >= discoveryRSSIDelta
+			emit := !known || (changed && now.Sub(entry.lastEmit) >= discoveryReportInterval)
+
+			var snapshot domain.DiscoveredDevice
+			if emit {
+				entry.lastEmit = now
+				entry.device.ServiceUUIDs = entry.device.ServiceUUIDs[:0]
+				for u := range entry.uuids {
+					entry.device.ServiceUUIDs = append(entry.device.ServiceUUIDs, u.String())
+				}
+				snapshot = entry.device
+				snapshot.ServiceUUIDs = append([]string(nil), entry.device.ServiceUUIDs...)
+				entry.lastEmitted = snapshot
+			}
+			mu.Unlock()
+
+			if emit {
+				onDevice(snapshot)
+			}
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			fmt.Printf("[BLE] Discovery scan error: %v\n", err)
+			return fmt.Errorf("Bluetooth scan error: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.adapter.StopScan()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		// Avoid blocking forever if the backend never returns.
+		fmt.Println("[BLE] Warning: discovery did not terminate 2s after StopScan")
+	}
+
+	mu.Lock()
+	fmt.Printf("[BLE] Discovery stopped. devices=%d\n", len(entries))
+	mu.Unlock()
+	return nil
+}
+
+// scanFor runs Discover for a fixed duration and returns the devices accepted by the filter.
+// On Windows the backend often does not expose advertised service UUIDs, so named
+// devices that could not be classified are listed too and validated on connect.
+func (s *RealService) scanFor(label string, duration time.Duration, accept func(domain.DiscoveredDevice) bool) ([]domain.BLEDevice, error) {
+	var foundDevices []domain.BLEDevice
+	// A map to avoid adding the same device twice (the radio picks up the same signal multiple times).
+	seen := make(map[string]bool)
+	var mu sync.Mutex
+
+	fmt.Printf("[BLE] Starting %d second scan by %s...\n", int(duration.Seconds()), label)
+
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+
+	err := s.Discover(ctx, func(d domain.DiscoveredDevice) {
+		isWindowsFallback := runtime.GOOS == "windows" && d.Type == domain.DeviceUnknown && d.Name != d.Address
+		if !accept(d) && !isWindowsFallback {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !seen[d.Address] {
+			seen[d.Address] = true
+			foundDevices = append(foundDevices, domain.BLEDevice{Name: d.Name, Address: d.Address})
+			fmt.Printf("[BLE] Found %s: %s (%s)\n", d.Type, d.Name, d.Address)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	fmt.Printf("[BLE] %s scan finished. found=%d\n", label, len(foundDevices))
+	return foundDevices, nil
+}
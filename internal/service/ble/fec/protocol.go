@@ -81,30 +81,52 @@ func EncodeTargetPower(watts float64) []byte {
 }
 
 // EncodeTrackResistance (Page 51) - Simulation Mode (Grid)
-func EncodeTrackResistance(grade float64) []byte {
+// crr <= 0 falls back to DefaultCrr.
+func EncodeTrackResistance(grade, crr float64) []byte {
 	// Grid: resolution 0.01%, offset 200.00%
 	// Ex: 5% -> (5 + 200) / 0.01 = 20500
 	rawGrade := uint16((grade + 200.0) / 0.01)
 	
 	// Crr: resolution 0.00005 (Auuki standard: 0.004 -> 80)
-	crr := byte(0.004 / 0.00005)
+	if crr <= 0 {
+		crr = DefaultCrr
+	}
 	
 	p := [7]byte{0xFF, 0xFF, 0xFF, 0xFF}
 	binary.LittleEndian.PutUint16(p[4:6], rawGrade)
-	p[6] = crr
+	p[6] = byte(clamp(crr/0.00005, 0, 254))
 	
 	return EncodeMessage(PageTrackResistance, p)
 }
 
+// Page 50 defaults (no wind, no draft)
+const (
+	DefaultWindResistance = 0.51 // kg/m
+	DefaultDraftingFactor = 1.0
+	DefaultCrr            = 0.004
+)
+
 // EncodeWindResistance (Page 50)
-func EncodeWindResistance() []byte {
+// windResistance: kg/m (resolution 0.01), windSpeed: km/h (offset 127, + = headwind),
+// draftingFactor: 0-1 (resolution 0.01, 1.0 = no drafting).
+func EncodeWindResistance(windResistance, windSpeedKmh, draftingFactor float64) []byte {
 	p := [7]byte{0x00, 0x00, 0x00, 0x00}
-	p[4] = byte(0.51 / 0.01) // windResistance
-	p[5] = 127               // windSpeed (0 + 127)
-	p[6] = byte(1.0 / 0.01)  // draftingFactor
+	p[4] = byte(clamp(windResistance/0.01, 0, 254)) // windResistance
+	p[5] = byte(clamp(windSpeedKmh+127, 0, 254))    // windSpeed
+	p[6] = byte(clamp(draftingFactor/0.01, 0, 100)) // draftingFactor
 	return EncodeMessage(PageWindResistance, p)
 }
 
+func clamp(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
 // EncodeBasicResistance (Page 48) - Used to reset or set manual resistance.
 func EncodeBasicResistance(resistance float64) []byte {
 	// Resolution: 0.5% (0 a 100%)
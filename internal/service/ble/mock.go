@@ -84,6 +84,10 @@ func (m *MockService) SetTrainerMode(mode string) {
 	// Mock doesn't need logic for this
 }
 
+func (m *MockService) SetSimulationParams(params domain.SimulationParams) error {
+	return nil
+}
+
 func (m *MockService) SubscribeStats(ch chan domain.Telemetry) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -20,6 +20,7 @@ import (
 	"argus-cyclist/internal/domain"
 	"argus-cyclist/internal/service/ble/fec"
 	"fmt"
+	"math"
 	"os"
 	"runtime"
 	"sync"
@@ -74,11 +75,13 @@ type RealService struct {
 	hrSubscribed      bool
 
 	// FEC Loop Control
-	targetPower  float64
-	targetGrade  float64
-	controlMutex sync.Mutex
-	stopControl  chan struct{}
-	isReady      bool
+	targetPower   float64
+	targetGrade   float64
+	simParams     domain.SimulationParams
+	simParamsSent bool // SetSimulationParams was called: FTMS grades go through 0x11
+	controlMutex  sync.Mutex
+	stopControl   chan struct{}
+	isReady       bool
 }
 
 func (s *RealService) enableAdapter() error {
@@ -122,6 +125,10 @@ func NewRealService() domain.TrainerService {
 		firstCrankRead: true,
 		currentMode:    "SIM",
 		stopControl:    make(chan struct{}),
+		simParams: domain.SimulationParams{
+			WindResistance: fec.DefaultWindResistance,
+			DraftingFactor: fec.DefaultDraftingFactor,
+		},
 	}
 }
 func (s *RealService) ConnectTrainer(macAddress string, onStatus func(string, string)) error {
@@ -317,7 +324,10 @@ func (s *RealService) initializeFEC() {
 	fmt.Println("[BLE] FEC Step 1: User Config Sent.")
 
 	time.Sleep(1000 * time.Millisecond)
-	msgWind := fec.EncodeWindResistance()
+	s.controlMutex.Lock()
+	simParams := s.simParams
+	s.controlMutex.Unlock()
+	msgWind := fec.EncodeWindResistance(simParams.WindResistance, simParams.WindSpeed*3.6, simParams.DraftingFactor)
 	s.fecWriteChar.WriteWithoutResponse(msgWind)
 	fmt.Println("[BLE] FEC Step 2: Wind Resistance Sent.")
 
@@ -345,7 +355,7 @@ func (s *RealService) runControlLoop() {
 			if s.currentMode == "ERG" {
 				msg = fec.EncodeTargetPower(s.targetPower)
 			} else {
-				msg = fec.EncodeTrackResistance(s.targetGrade)
+				msg = fec.EncodeTrackResistance(s.targetGrade, s.simParams.Crr)
 			}
 			s.controlMutex.Unlock()
 
@@ -377,13 +387,19 @@ func (s *RealService) SetGrade(grade float64) error {
 	fecWriteChar := s.fecWriteChar
 	trainerPointChar := s.trainerPointChar
 	isReady := s.isReady
+	simParams := s.simParams
+	simParamsSent := s.simParamsSent
 	s.controlMutex.Unlock()
 
 	if isFEC && fecWriteChar != nil && isReady {
-		msg := fec.EncodeTrackResistance(grade)
+		msg := fec.EncodeTrackResistance(grade, simParams.Crr)
 		go fecWriteChar.WriteWithoutResponse(msg)
+	} else if !isFEC && trainerPointChar != nil && simParamsSent {
+		// Once simulation parameters were sent, stay in simulation mode so
+		// every grade change carries the current wind, Crr and draft.
+		go trainerPointChar.WriteWithoutResponse(encodeFTMSSimulation(grade, simParams))
 	} else if !isFEC && trainerPointChar != nil {
-		// Control for Pure FTMS Protocol (Sim Mode / Inclination)
+		// Pure FTMS before any simulation parameters were sent
 		// Opcode: 0x03 (Set Target Inclination)
 		// Resolution: 0.1%, Format: sint16 (Little Endian)
 		val := int16(grade * 10.0)
@@ -393,6 +409,51 @@ func (s *RealService) SetGrade(grade float64) error {
 	return nil
 }
 
+// SetSimulationParams updates wind resistance, wind speed, drafting factor and Crr.
+// FE-C receives page 50; pure FTMS receives the Indoor Bike Simulation
+// Parameters opcode, which carries the current grade as well.
+func (s *RealService) SetSimulationParams(params domain.SimulationParams) error {
+	s.controlMutex.Lock()
+	s.simParams = params
+	s.simParamsSent = true
+	isFEC := s.isFEC
+	fecWriteChar := s.fecWriteChar
+	trainerPointChar := s.trainerPointChar
+	isReady := s.isReady
+	grade := s.targetGrade
+	isERG := s.currentMode == "ERG"
+	s.controlMutex.Unlock()
+
+	if isERG {
+		return nil
+	}
+
+	if isFEC && fecWriteChar != nil && isReady {
+		msg := fec.EncodeWindResistance(params.WindResistance, params.WindSpeed*3.6, params.DraftingFactor)
+		go fecWriteChar.WriteWithoutResponse(msg)
+		// Crr travels on page 51 together with the grade
+		go fecWriteChar.WriteWithoutResponse(fec.EncodeTrackResistance(grade, params.Crr))
+	} else if !isFEC && trainerPointChar != nil {
+		go trainerPointChar.WriteWithoutResponse(encodeFTMSSimulation(grade, params))
+	}
+	return nil
+}
+
+// encodeFTMSSimulation builds the FTMS "Set Indoor Bike Simulation Parameters" command.
+// Opcode: 0x11
+// Wind speed: sint16, 0.001 m/s | Grade: sint16, 0.01% | Crr: uint8, 0.0001 | Cw: uint8, 0.01 kg/m
+// FTMS has no drafting factor, so the draft is applied to the wind resistance coefficient.
+func encodeFTMSSimulation(grade float64, params domain.SimulationParams) []byte {
+	windSpeed := int16(math.Max(-32.767, math.Min(32.767, params.WindSpeed)) * 1000.0)
+	g := int16(grade * 100.0)
+	crr := params.Crr
+	if crr <= 0 {
+		crr = fec.DefaultCrr
+	}
+	cw := byte(params.WindResistance * params.DraftingFactor / 0.01)
+	return []byte{0x11, byte(windSpeed & 0xFF), byte(windSpeed >> 8), byte(g & 0xFF), byte(g >> 8), byte(math.Min(crr/0.0001, 255)), cw}
+}
+
 func (s *RealService) SetPower(watts float64) error {
 	s.controlMutex.Lock()
 	s.targetPower = watts
@@ -509,160 +570,10 @@ func (s *RealService) DisconnectHR() {
 
 // ScanForTrainers connects the antenna, searches for compatible reels for 5 seconds, and returns the list.
 func (s *RealService) ScanForTrainers() ([]domain.BLEDevice, error) {
-	if err := s.enableAdapter(); err != nil {
-		return nil, fmt.Errorf("Bluetooth error: %w", err)
-	}
-
-	var foundDevices []domain.BLEDevice
-	// I a map to avoid adding the same device twice (the radio picks up the same signal multiple times).
-	seen := make(map[string]bool)
-	var mu sync.Mutex
-	scanErrCh := make(chan error, 1)
-	done := make(chan struct{})
-	var callbackCount uint64
-
-	fmt.Println("[BLE] Starting 5 second scan by Smart Trainers...")
-
-	go func() {
-		err := s.adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
-			callbackCount++
-			if bleDebugEnabled() {
-				fmt.Printf("[BLE][SCAN][%s][TRAINER] addr=%s name=%q rssi=%d hasCP=%v hasFTMS=%v hasFEC=%v hasFEC128=%v\n",
-					runtime.GOOS,
-					result.Address.String(),
-					result.LocalName(),
-					result.RSSI,
-					result.HasServiceUUID(ServiceCyclingPower),
-					result.HasServiceUUID(ServiceFitnessMach),
-					result.HasServiceUUID(ServiceFEC),
-					result.HasServiceUUID(ServiceFEC128),
-				)
-			}
-			// Checks if it is a Trainer (FTMS or FE-C).
-			// On Windows, the backend often does not expose advertised service UUIDs in ScanResult,
-			// so we provide a fallback that lists named devices and validates services on connect.
-			isTrainerAdv := result.HasServiceUUID(ServiceCyclingPower) ||
-				result.HasServiceUUID(ServiceFitnessMach) ||
-				result.HasServiceUUID(ServiceFEC) ||
-				result.HasServiceUUID(ServiceFEC128)
-			isWindowsFallback := runtime.GOOS == "windows" && result.LocalName() != ""
-
-			if isTrainerAdv || isWindowsFallback {
-
-				mac := result.Address.String()
-				mu.Lock()
-				if !seen[mac] {
-					seen[mac] = true
-					name := scanDisplayName(result)
-					foundDevices = append(foundDevices, domain.BLEDevice{Name: name, Address: mac})
-					fmt.Printf("[BLE] Found: %s (%s)\n", name, mac)
-				}
-				mu.Unlock()
-			}
-		})
-		if err != nil {
-			// Always print the underlying backend error (Windows WinRT errors, etc).
-			fmt.Printf("[BLE] ScanForTrainers scan error: %v\n", err)
-			select {
-			case scanErrCh <- err:
-			default:
-			}
-		}
-		close(done)
-	}()
-
-	time.Sleep(5 * time.Second)
-	s.adapter.StopScan()
-	fmt.Printf("[BLE] StopScan requested (trainer scan). callbacks=%d\n", callbackCount)
-	select {
-	case <-done:
-	case <-time.After(2 * time.Second):
-		// Avoid blocking forever if the backend never returns.
-		fmt.Println("[BLE] Warning: scan did not terminate 2s after StopScan")
-	}
-
-	select {
-	case err := <-scanErrCh:
-		return nil, fmt.Errorf("Bluetooth scan error: %w", err)
-	default:
-	}
-
-	mu.Lock()
-	defer mu.Unlock()
-	fmt.Printf("[BLE] Trainer scan finished. found=%d callbacks=%d\n", len(foundDevices), callbackCount)
-	return foundDevices, nil
+	return s.scanFor("Smart Trainers", 5*time.Second, IsTrainerDevice)
 }
 
 // ScanForHR activates the antenna, searches for heart rate monitors for 5 seconds, and returns the list.
 func (s *RealService) ScanForHR() ([]domain.BLEDevice, error) {
-	if err := s.enableAdapter(); err != nil {
-		return nil, fmt.Errorf("Bluetooth error: %w", err)
-	}
-
-	var foundDevices []domain.BLEDevice
-	seen := make(map[string]bool)
-	var mu sync.Mutex
-	scanErrCh := make(chan error, 1)
-	done := make(chan struct{})
-	var callbackCount uint64
-
-	fmt.Println("[BLE] Starting 5 second scan by HR Monitors...")
-
-	go func() {
-		err := s.adapter.Scan(func(adapter *bluetooth.Adapter, result bluetooth.ScanResult) {
-			callbackCount++
-			if bleDebugEnabled() {
-				fmt.Printf("[BLE][SCAN][%s][HR] addr=%s name=%q rssi=%d hasHR=%v\n",
-					runtime.GOOS,
-					result.Address.String(),
-					result.LocalName(),
-					result.RSSI,
-					result.HasServiceUUID(ServiceHeartRate),
-				)
-			}
-			// Similar Windows fallback: advertised HR service UUID may be unavailable in ScanResult.
-			isHRAdv := result.HasServiceUUID(ServiceHeartRate)
-			isWindowsFallback := runtime.GOOS == "windows" && result.LocalName() != ""
-
-			if isHRAdv || isWindowsFallback {
-				mac := result.Address.String()
-				mu.Lock()
-				if !seen[mac] {
-					seen[mac] = true
-					name := scanDisplayName(result)
-					foundDevices = append(foundDevices, domain.BLEDevice{Name: name, Address: mac})
-					fmt.Printf("[BLE] HR Found: %s (%s)\n", name, mac)
-				}
-				mu.Unlock()
-			}
-		})
-		if err != nil {
-			fmt.Printf("[BLE] ScanForHR scan error: %v\n", err)
-			select {
-			case scanErrCh <- err:
-			default:
-			}
-		}
-		close(done)
-	}()
-
-	time.Sleep(5 * time.Second)
-	s.adapter.StopScan()
-	fmt.Printf("[BLE] StopScan requested (hr scan). callbacks=%d\n", callbackCount)
-	select {
-	case <-done:
-	case <-time.After(2 * time.Second):
-		fmt.Println("[BLE] Warning: scan did not terminate 2s after StopScan")
-	}
-
-	select {
-	case err := <-scanErrCh:
-		return nil, fmt.Errorf("Bluetooth scan error: %w", err)
-	default:
-	}
-
-	mu.Lock()
-	defer mu.Unlock()
-	fmt.Printf("[BLE] HR scan finished. found=%d callbacks=%d\n", len(foundDevices), callbackCount)
-	return foundDevices, nil
+	return s.scanFor("HR Monitors", 5*time.Second, IsHRDevice)
 }
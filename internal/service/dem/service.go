@@ -0,0 +1,172 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// voidValue marks missing samples in SRTM tiles.
+const voidValue = -32768
+
+// tile is one 1°x1° SRTM .hgt file: size x size big-endian int16 samples,
+// rows from north to south. data is nil when the tile is not available,
+// and err is set when the file exists but is not a valid tile.
+type tile struct {
+	size int
+	data []int16
+	err  error
+}
+
+// Service samples elevations from SRTM .hgt tiles stored in a directory
+// (e.g. N45E006.hgt). Tiles are loaded on demand and cached.
+type Service struct {
+	dir   string
+	mu    sync.Mutex
+	tiles map[string]*tile
+}
+
+func NewService(dir string) *Service {
+	return &Service{dir: dir, tiles: make(map[string]*tile)}
+}
+
+// Dir returns the tile directory.
+func (s *Service) Dir() string {
+	return s.dir
+}
+
+// TileName returns the SRTM name of the tile containing the coordinate.
+func TileName(lat, lon float64) string {
+	latI := int(math.Floor(lat))
+	lonI := int(math.Floor(lon))
+
+	ns, ew := "N", "E"
+	if latI < 0 {
+		ns = "S"
+	}
+	if lonI < 0 {
+		ew = "W"
+	}
+	return fmt.Sprintf("%s%02d%s%03d", ns, abs(latI), ew, abs(lonI))
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// loadTile reads (or returns the cached) tile; missing tiles are cached as
+// empty and invalid ones with their error.
+func (s *Service) loadTile(name string) (*tile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.tiles[name]; ok {
+		if t.err != nil {
+			return nil, t.err
+		}
+		return t, nil
+	}
+
+	t := &tile{}
+	s.tiles[name] = t
+
+	var raw []byte
+	var err error
+	for _, candidate := range []string{name + ".hgt", strings.ToLower(name) + ".hgt"} {
+		raw, err = os.ReadFile(filepath.Join(s.dir, candidate))
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return t, nil
+	}
+
+	samples := len(raw) / 2
+	size := int(math.Sqrt(float64(samples)))
+	if size*size != samples || (size != 1201 && size != 3601) {
+		t.err = fmt.Errorf("invalid SRTM tile %s: %d bytes", name, len(raw))
+		return nil, t.err
+	}
+
+	t.size = size
+	t.data = make([]int16, samples)
+	for i := range t.data {
+		t.data[i] = int16(binary.BigEndian.Uint16(raw[i*2:]))
+	}
+	return t, nil
+}
+
+// Elevation returns the bilinearly interpolated elevation (m) at a coordinate.
+// Void samples are ignored; an error is returned if no tile or sample covers the point.
+func (s *Service) Elevation(lat, lon float64) (float64, error) {
+	name := TileName(lat, lon)
+	t, err := s.loadTile(name)
+	if err != nil {
+		return 0, err
+	}
+	if t.data == nil {
+		return 0, fmt.Errorf("missing SRTM tile %s", name)
+	}
+
+	// Position inside the tile in sample units (row 0 = north edge)
+	row := (math.Floor(lat) + 1 - lat) * float64(t.size-1)
+	col := (lon - math.Floor(lon)) * float64(t.size-1)
+
+	r0 := int(math.Min(math.Floor(row), float64(t.size-2)))
+	c0 := int(math.Min(math.Floor(col), float64(t.size-2)))
+	fr := row - float64(r0)
+	fc := col - float64(c0)
+
+	corners := [4]struct {
+		v      int16
+		weight float64
+	}{
+		{t.data[r0*t.size+c0], (1 - fr) * (1 - fc)},
+		{t.data[r0*t.size+c0+1], (1 - fr) * fc},
+		{t.data[(r0+1)*t.size+c0], fr * (1 - fc)},
+		{t.data[(r0+1)*t.size+c0+1], fr * fc},
+	}
+
+	sum, weights := 0.0, 0.0
+	for _, c := range corners {
+		if c.v == voidValue {
+			continue
+		}
+		sum += float64(c.v) * c.weight
+		weights += c.weight
+	}
+	if weights == 0 {
+		// Exactly on a sample next to voids, or only voids around
+		for _, c := range corners {
+			if c.v != voidValue {
+				return float64(c.v), nil
+			}
+		}
+		return 0, fmt.Errorf("no SRTM data at %.5f, %.5f", lat, lon)
+	}
+	return sum / weights, nil
+}
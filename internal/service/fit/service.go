@@ -37,11 +37,14 @@ const degreesToSemicircles = 2147483648.0 / 180.0
 
 type Service struct {
 	records   []*mesgdef.Record
+	laps      []*mesgdef.Lap
+	markers   []*mesgdef.Event
 	startTime time.Time
 }
 
 type ActivityDetails struct {
 	Time      []string  `json:"time"`
+	Elapsed   []float64 `json:"elapsed"` // Seconds since the first record
 	Power     []int     `json:"power"`
 	HeartRate []int     `json:"hr"`
 	Cadence   []int     `json:"cadence"`
@@ -64,6 +67,8 @@ func (s *Service) GetRecordCount() int {
 func (s *Service) StartSession(startTime time.Time) {
 	s.startTime = startTime
 	s.records = []*mesgdef.Record{} // Clears previous records
+	s.laps = nil
+	s.markers = nil
 }
 
 // AddRecord converts app telemetry to FIT binary format
@@ -99,6 +104,48 @@ func (s *Service) AddRecord(t domain.Telemetry) {
 	s.records = append(s.records, record)
 }
 
+// AddLap stores a lap summary, written as a Lap message on Save.
+func (s *Service) AddLap(l domain.LapStats) {
+	trigger := typedef.LapTriggerDistance
+	if !l.Complete {
+		trigger = typedef.LapTriggerSessionEnd
+	}
+
+	lap := &mesgdef.Lap{
+		MessageIndex:     typedef.MessageIndex(len(s.laps)),
+		Timestamp:        l.EndTime,
+		StartTime:        l.StartTime,
+		TotalElapsedTime: uint32(l.EndTime.Sub(l.StartTime).Seconds() * 1000), // ms
+		TotalTimerTime:   uint32(l.Duration * 1000),                           // ms
+		TotalDistance:    uint32(l.Distance * 100),                            // cm
+		EnhancedAvgSpeed: uint32(l.AvgSpeed / 3.6 * 1000),                     // mm/s
+		EnhancedMaxSpeed: uint32(l.MaxSpeed / 3.6 * 1000),                     // mm/s
+		AvgPower:         uint16(l.AvgPower),
+		MaxPower:         uint16(l.MaxPower),
+		AvgHeartRate:     uint8(l.AvgHR),
+		MaxHeartRate:     uint8(l.MaxHR),
+		AvgCadence:       uint8(l.AvgCadence),
+		TotalAscent:      uint16(l.ElevationGain),
+		Event:            typedef.EventLap,
+		EventType:        typedef.EventTypeStop,
+		LapTrigger:       trigger,
+		Sport:            typedef.SportCycling,
+		SubSport:         typedef.SubSportVirtualActivity,
+	}
+	s.laps = append(s.laps, lap)
+}
+
+// AddCoursePointEvent stores a course point marker (a point of interest
+// passed), written as an Event message on Save. Data carries the POI index.
+func (s *Service) AddCoursePointEvent(index int, at time.Time) {
+	s.markers = append(s.markers, &mesgdef.Event{
+		Timestamp: at,
+		Event:     typedef.EventCoursePoint,
+		EventType: typedef.EventTypeMarker,
+		Data:      uint32(index),
+	})
+}
+
 // Save finalizes the file, calculates session totals, and writes to disk
 func (s *Service) Save(filepath string) error {
 	f, err := os.Create(filepath)
@@ -129,6 +176,9 @@ func (s *Service) Save(filepath string) error {
 	for _, rec := range s.records {
 		fit.Messages = append(fit.Messages, rec.ToMesg(nil))
 	}
+	for _, marker := range s.markers {
+		fit.Messages = append(fit.Messages, marker.ToMesg(nil))
+	}
 
 	// 5. Calculations for Summary
 	totalTime := time.Since(s.startTime).Seconds()
@@ -143,7 +193,10 @@ func (s *Service) Save(filepath string) error {
 	}
 	fit.Messages = append(fit.Messages, eventMesg.ToMesg(nil))
 
-	// 7. Lap Message
+	// 7. Lap Messages (a single lap covering the session when no laps were recorded)
+	for _, lap := range s.laps {
+		fit.Messages = append(fit.Messages, lap.ToMesg(nil))
+	}
 	lapMesg := mesgdef.Lap{
 		Timestamp:        time.Now(),
 		StartTime:        s.startTime,
@@ -154,7 +207,9 @@ func (s *Service) Save(filepath string) error {
 		Event:            typedef.EventLap,
 		EventType:        typedef.EventTypeStop,
 	}
-	fit.Messages = append(fit.Messages, lapMesg.ToMesg(nil))
+	if len(s.laps) == 0 {
+		fit.Messages = append(fit.Messages, lapMesg.ToMesg(nil))
+	}
 
 	// 8. Session Message (Final Summary)
 	sessionMesg := mesgdef.Session{
@@ -292,6 +347,7 @@ func (s *Service) ParseActivity(filePath string) (ActivityDetails, error) {
 
 	details := ActivityDetails{
 		Time:      []string{},
+		Elapsed:   []float64{},
 		Power:     []int{},
 		HeartRate: []int{},
 		Cadence:   []int{},
@@ -314,6 +370,7 @@ func (s *Service) ParseActivity(filePath string) (ActivityDetails, error) {
 			m := totalSecs / 60
 			s := totalSecs % 60
 			details.Time = append(details.Time, fmt.Sprintf("%02d:%02d", m, s))
+			details.Elapsed = append(details.Elapsed, elapsed.Seconds())
 
 			details.Power = append(details.Power, int(record.Power))
 			details.HeartRate = append(details.HeartRate, int(record.HeartRate))
@@ -0,0 +1,187 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"fmt"
+	"math"
+
+	"argus-cyclist/internal/domain"
+)
+
+// Kinds of catalogue routes
+const (
+	CatalogueClimb     = "climb"
+	CatalogueBenchmark = "benchmark"
+)
+
+// CatalogueRoute is an embedded route: a well-known climb, rebuilt from its
+// published gradients at its real start, or a standard benchmark course.
+type CatalogueRoute struct {
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Kind          string  `json:"kind"`
+	Country       string  `json:"country"`
+	Description   string  `json:"description"`
+	Distance      float64 `json:"distance"`       // m
+	ElevationGain float64 `json:"elevation_gain"` // m
+	AvgGrade      float64 `json:"avg_grade"`      // % start to finish
+	MaxGrade      float64 `json:"max_grade"`      // % steepest leg
+
+	origin SynthOptions
+	legs   []RouteLeg
+}
+
+// km builds legs of one kilometre from a list of grades.
+func km(grades ...float64) []RouteLeg {
+	legs := make([]RouteLeg, len(grades))
+	for i, g := range grades {
+		legs[i] = RouteLeg{Distance: 1000, Grade: g}
+	}
+	return legs
+}
+
+func leg(distance, grade float64) []RouteLeg {
+	return []RouteLeg{{Distance: distance, Grade: grade}}
+}
+
+func legs(parts ...[]RouteLeg) []RouteLeg {
+	var all []RouteLeg
+	for _, p := range parts {
+		all = append(all, p...)
+	}
+	return all
+}
+
+var catalogue = []CatalogueRoute{
+	{
+		ID: "alpe-dhuez", Name: "Alpe d'Huez", Kind: CatalogueClimb, Country: "France",
+		Description: "The 21 hairpins from Le Bourg-d'Oisans.",
+		origin:      SynthOptions{StartLatitude: 45.0553, StartLongitude: 6.0317, StartElevation: 740, Heading: 80},
+		legs:        legs(km(10.4, 9.9, 7.9, 9.4, 7.6, 8.6, 8.8, 9.0, 7.1, 7.3, 8.9, 6.8, 5.5), leg(800, 0.5)),
+	},
+	{
+		ID: "mont-ventoux", Name: "Mont Ventoux (Bédoin)", Kind: CatalogueClimb, Country: "France",
+		Description: "Gentle start, the forest at 9% and the exposed moonscape to the summit.",
+		origin:      SynthOptions{StartLatitude: 44.1246, StartLongitude: 5.1789, StartElevation: 296, Heading: 20},
+		legs:        legs(km(3.5, 4.2, 4.5, 5.0, 4.8), km(9.0, 9.4, 9.8, 9.2, 9.0, 9.3, 9.5, 8.9, 9.1, 8.8), km(7.0, 7.6, 6.8, 7.2, 7.9, 7.4), leg(500, 9.0)),
+	},
+	{
+		ID: "tourmalet", Name: "Col du Tourmalet (Luz-Saint-Sauveur)", Kind: CatalogueClimb, Country: "France",
+		Description: "The western side through Barèges, steepening towards the pass.",
+		origin:      SynthOptions{StartLatitude: 42.8727, StartLongitude: -0.0037, StartElevation: 711, Heading: 90},
+		legs:        legs(km(5.0, 5.5, 6.0, 5.2, 5.8), km(7.4, 7.8, 8.2, 7.6, 7.9, 8.0, 7.5, 8.1, 7.7), km(8.3, 8.8, 9.0, 8.6, 8.4)),
+	},
+	{
+		ID: "stelvio", Name: "Passo dello Stelvio (Prato)", Kind: CatalogueClimb, Country: "Italy",
+		Description: "48 hairpins from Prato allo Stelvio to the 2758 m pass.",
+		origin:      SynthOptions{StartLatitude: 46.6196, StartLongitude: 10.5906, StartElevation: 915, Heading: 200},
+		legs:        legs(km(5.2, 5.6, 6.0, 5.8, 6.2, 6.0), km(7.5, 8.1, 7.7, 8.2, 7.9, 7.6, 8.3, 8.0, 7.8, 7.9, 8.0, 7.8), km(8.5, 9.0, 8.8, 8.6, 8.7, 8.9), leg(300, 8.0)),
+	},
+	{
+		ID: "mortirolo", Name: "Passo del Mortirolo (Mazzo)", Kind: CatalogueClimb, Country: "Italy",
+		Description: "Relentless double-digit gradients through the woods above Mazzo di Valtellina.",
+		origin:      SynthOptions{StartLatitude: 46.2583, StartLongitude: 10.2558, StartElevation: 552, Heading: 130},
+		legs:        legs(km(7.2, 7.8), km(11.0, 12.4, 12.8, 13.1, 12.0, 11.8, 12.3), km(9.5, 8.8, 8.2), leg(400, 9.0)),
+	},
+	{
+		ID: "angliru", Name: "Alto de l'Angliru (Riosa)", Kind: CatalogueClimb, Country: "Spain",
+		Description: "A steady first half, then the Cueña les Cabres ramps above 20%.",
+		origin:      SynthOptions{StartLatitude: 43.2372, StartLongitude: -5.8875, StartElevation: 335, Heading: 240},
+		legs:        legs(km(6.5, 7.0, 7.8, 8.0, 7.2, 8.5), km(10.5, 13.0, 14.2), leg(500, 23.5), leg(1000, 12.0), km(11.5), leg(500, 9.0)),
+	},
+	{
+		ID: "mur-de-huy", Name: "Mur de Huy", Kind: CatalogueClimb, Country: "Belgium",
+		Description: "The short, brutal wall of the Flèche Wallonne finish.",
+		origin:      SynthOptions{StartLatitude: 50.5187, StartLongitude: 5.2390, StartElevation: 80, Heading: 180},
+		legs:        legs(leg(400, 6.0), leg(300, 11.0), leg(300, 19.0), leg(300, 9.0)),
+	},
+	{
+		ID: "arenberg", Name: "Trouée d'Arenberg", Kind: CatalogueBenchmark, Country: "France",
+		Description: "The Paris-Roubaix cobbled sector through the Arenberg forest.",
+		origin:      SynthOptions{StartLatitude: 50.3987, StartLongitude: 3.4108, StartElevation: 28, Heading: 315},
+		legs:        []RouteLeg{{Distance: 2300, Grade: 0.1, Surface: domain.SurfaceCobbles}},
+	},
+	{
+		ID: "tt-10mi", Name: "10 mile time trial", Kind: CatalogueBenchmark,
+		Description: "Flat 10 miles (16.1 km), the club time trial standard.",
+		origin:      KOMOrigin,
+		legs:        leg(16093, 0),
+	},
+	{
+		ID: "tt-40k", Name: "40 km time trial", Kind: CatalogueBenchmark,
+		Description: "Flat 40 km, the classic test of sustained power.",
+		origin:      KOMOrigin,
+		legs:        leg(40000, 0),
+	},
+	{
+		ID: "climb-test-8k", Name: "8 km climbing test", Kind: CatalogueBenchmark,
+		Description: "A steady 6% climb, for FTP tests at climbing cadence.",
+		origin:      KOMOrigin,
+		legs:        legs(leg(1000, 0), leg(8000, 6), leg(500, 0)),
+	},
+	{
+		ID: "rolling-100k", Name: "Rolling 100 km", Kind: CatalogueBenchmark,
+		Description: "Ten 10 km laps of a 3-6% hill, for endurance and pacing work.",
+		origin:      KOMOrigin,
+		legs:        rollingLegs(10),
+	},
+}
+
+// rollingLegs repeats a 10 km loop: flat, a hill and its descent.
+func rollingLegs(laps int) []RouteLeg {
+	var all []RouteLeg
+	for i := 0; i < laps; i++ {
+		all = append(all, legs(leg(4000, 0), leg(1000, 3), leg(1500, 6), leg(500, 3), leg(3000, -4.5))...)
+	}
+	return all
+}
+
+func init() {
+	for i := range catalogue {
+		r := &catalogue[i]
+		ele := r.origin.StartElevation
+		for _, l := range r.legs {
+			rise := l.Distance * math.Sin(math.Atan(l.Grade/100))
+			r.Distance += l.Distance
+			r.ElevationGain += math.Max(rise, 0)
+			r.MaxGrade = math.Max(r.MaxGrade, l.Grade)
+			ele += rise
+		}
+		r.AvgGrade = (ele - r.origin.StartElevation) / r.Distance * 100
+	}
+}
+
+// Catalogue lists the embedded routes.
+func Catalogue() []CatalogueRoute {
+	return append([]CatalogueRoute(nil), catalogue...)
+}
+
+// CatalogueRouteByID finds an embedded route.
+func CatalogueRouteByID(id string) (CatalogueRoute, error) {
+	for _, r := range catalogue {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return CatalogueRoute{}, fmt.Errorf("unknown catalogue route: %s", id)
+}
+
+// Build synthesises the route's points.
+func (r CatalogueRoute) Build() ([]domain.RoutePoint, error) {
+	return SynthesizeRoute(r.legs, r.origin)
+}
@@ -0,0 +1,193 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"math"
+
+	"argus-cyclist/internal/domain"
+)
+
+// Climb categories, easiest first
+const (
+	ClimbCat4 = "4"
+	ClimbCat3 = "3"
+	ClimbCat2 = "2"
+	ClimbCat1 = "1"
+	ClimbHC   = "HC"
+)
+
+const (
+	climbMinGrade  = 3.0  // % average, below that it is a drag, not a climb
+	climbMinDrop   = 10.0 // m of descent that ends a climb...
+	climbDropPart  = 0.1  // ...or this fraction of the gain so far, if larger
+	climbMaxFlat   = 500  // m without a new top that ends a climb
+	climbStartRise = 1.0  // m above the low point where the climb starts (ignores flat noise)
+)
+
+// climbCategories are the minimum scores (length m × average grade %) per category.
+var climbCategories = []struct {
+	score    float64
+	category string
+}{
+	{80000, ClimbHC},
+	{64000, ClimbCat1},
+	{32000, ClimbCat2},
+	{16000, ClimbCat3},
+	{8000, ClimbCat4},
+}
+
+// Climb is a categorised ascent of the route.
+type Climb struct {
+	Index          int     `json:"index"` // 1-based, in route order
+	Start          float64 `json:"start"` // m
+	End            float64 `json:"end"`   // m
+	Length         float64 `json:"length"`
+	AvgGrade       float64 `json:"avg_grade"`
+	MaxGrade       float64 `json:"max_grade"`
+	Gain           float64 `json:"gain"` // m, bottom to top
+	StartElevation float64 `json:"start_elevation"`
+	TopElevation   float64 `json:"top_elevation"`
+	Score          float64 `json:"score"`
+	Category       string  `json:"category"`
+}
+
+// ClimbCategory returns the category for a climb score ("" if uncategorised).
+func ClimbCategory(score float64) string {
+	for _, c := range climbCategories {
+		if score >= c.score {
+			return c.category
+		}
+	}
+	return ""
+}
+
+// GetClimbs returns the categorised climbs of the loaded route.
+func (s *Service) GetClimbs() []Climb {
+	return s.climbs
+}
+
+// updateClimbs re-detects the climbs after the route points changed.
+func (s *Service) updateClimbs() {
+	s.climbs = detectClimbs(s.points)
+	s.reverseClimbs = nil
+	if len(s.points) >= 2 {
+		s.reverseClimbs = detectClimbs(reverseProfile(s.points))
+	}
+}
+
+// ClimbAt returns the climb under a course position and the distance left to
+// its top (nil when not climbing). On the return leg of an out-and-back the
+// climbs of the reversed profile are used.
+func (s *Service) ClimbAt(c CoursePosition) (*Climb, float64) {
+	climbs, pos := s.climbs, c.Position
+	if c.Reversed {
+		climbs, pos = s.reverseClimbs, s.GetTotalDistance()-c.Position
+	}
+	for _, climb := range climbs {
+		if pos >= climb.Start && pos < climb.End {
+			return &climb, climb.End - pos
+		}
+	}
+	return nil, 0
+}
+
+// detectClimbs walks the profile from each low point to the following top,
+// tolerating short dips, and keeps the ascents that reach Cat 4.
+func detectClimbs(points []domain.RoutePoint) []Climb {
+	var climbs []Climb
+	if len(points) < 2 {
+		return climbs
+	}
+
+	bottom, top := 0, 0
+	finish := func() {
+		if climb, ok := makeClimb(points[bottom : top+1]); ok {
+			climb.Index = len(climbs) + 1
+			climbs = append(climbs, climb)
+		}
+	}
+
+	for i := 1; i < len(points); i++ {
+		ele := points[i].Elevation
+		switch {
+		case ele > points[top].Elevation:
+			top = i
+		case ele <= points[bottom].Elevation && top == bottom:
+			bottom, top = i, i
+		default:
+			gain := points[top].Elevation - points[bottom].Elevation
+			drop := points[top].Elevation - ele
+			flat := points[i].Distance - points[top].Distance
+			if drop > math.Max(climbMinDrop, gain*climbDropPart) || ele <= points[bottom].Elevation || flat > climbMaxFlat {
+				finish()
+				bottom, top = i, i
+			}
+		}
+	}
+	finish()
+	return climbs
+}
+
+func makeClimb(points []domain.RoutePoint) (Climb, bool) {
+	// Start where the road leaves the low point, not somewhere along the flat before it
+	low := points[0].Elevation
+	for len(points) > 1 && points[1].Elevation <= low+climbStartRise {
+		points = points[1:]
+	}
+	first, last := points[0], points[len(points)-1]
+	length := last.Distance - first.Distance
+	if length <= 0 {
+		return Climb{}, false
+	}
+	gain := last.Elevation - first.Elevation
+	avg := gain / length * 100
+	score := length * avg
+	category := ClimbCategory(score)
+	if avg < climbMinGrade || category == "" {
+		return Climb{}, false
+	}
+
+	maxGrade := 0.0
+	for _, p := range points {
+		maxGrade = math.Max(maxGrade, p.Grade)
+	}
+	return Climb{
+		Start:          first.Distance,
+		End:            last.Distance,
+		Length:         length,
+		AvgGrade:       avg,
+		MaxGrade:       maxGrade,
+		Gain:           gain,
+		StartElevation: first.Elevation,
+		TopElevation:   last.Elevation,
+		Score:          score,
+		Category:       category,
+	}, true
+}
+
+// reverseProfile is the route ridden backwards, with distances from the far end.
+func reverseProfile(points []domain.RoutePoint) []domain.RoutePoint {
+	total := points[len(points)-1].Distance
+	out := make([]domain.RoutePoint, len(points))
+	for i, p := range points {
+		p.Distance = total - p.Distance
+		p.Grade = -p.Grade
+		out[len(points)-1-i] = p
+	}
+	return out
+}
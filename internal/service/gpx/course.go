@@ -0,0 +1,98 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"fmt"
+	"math"
+
+	"argus-cyclist/internal/domain"
+)
+
+// What happens when the rider reaches the end of the route
+const (
+	CourseLoop       = "loop"         // Start again from the first point
+	CourseFinish     = "finish"       // Stop at the last point
+	CourseOutAndBack = "out_and_back" // Turn around and ride the route backwards to the start
+)
+
+// CoursePosition maps a ridden distance onto the route.
+type CoursePosition struct {
+	Position float64 // Distance along the route's points (m)
+	Reversed bool    // Riding the route backwards (out-and-back return leg)
+	Lap      int     // Completed laps
+	Finished bool    // Reached the end of a finish-mode route
+}
+
+// ValidateCourseMode rejects unknown course modes.
+func ValidateCourseMode(mode string) error {
+	switch mode {
+	case CourseLoop, CourseFinish, CourseOutAndBack:
+		return nil
+	}
+	return fmt.Errorf("unknown route mode: %s", mode)
+}
+
+// LapLength is the distance of one lap for the mode (0 if the route is empty).
+func LapLength(mode string, total float64) float64 {
+	if mode == CourseOutAndBack {
+		return 2 * total
+	}
+	return total
+}
+
+// Locate converts a ridden distance into a position on a route of length total.
+func Locate(mode string, distance, total float64) CoursePosition {
+	if total <= 0 {
+		return CoursePosition{Position: distance}
+	}
+	if distance < 0 {
+		// Behind the start (e.g. virtual riders spawned behind the user)
+		if mode == CourseLoop {
+			return CoursePosition{Position: math.Mod(distance, total) + total}
+		}
+		distance = 0
+	}
+
+	switch mode {
+	case CourseFinish:
+		if distance >= total {
+			return CoursePosition{Position: total, Lap: 1, Finished: true}
+		}
+		return CoursePosition{Position: distance}
+	case CourseOutAndBack:
+		lapLength := 2 * total
+		pos := math.Mod(distance, lapLength)
+		c := CoursePosition{Position: pos, Lap: int(distance / lapLength)}
+		if pos > total {
+			c.Position = lapLength - pos
+			c.Reversed = true
+		}
+		return c
+	}
+	return CoursePosition{Position: math.Mod(distance, total), Lap: int(distance / total)}
+}
+
+// GetCoursePoint returns the route point at a course position. On the return
+// leg of an out-and-back the grade is inverted.
+func (s *Service) GetCoursePoint(c CoursePosition) domain.RoutePoint {
+	p := s.GetPointAtDistance(c.Position)
+	if c.Reversed {
+		p.Grade = -p.Grade
+	}
+	return p
+}
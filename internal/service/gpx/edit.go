@@ -0,0 +1,171 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"fmt"
+	"math"
+
+	"argus-cyclist/internal/domain"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// maxChainGap is how far (m) one chained route may end from the next one's start.
+const maxChainGap = 200.0
+
+// Slice keeps only the part of the route between two distances (m). The
+// new route starts at distance 0.
+func (s *Service) Slice(start, end float64) error {
+	if len(s.raw) < 2 {
+		return fmt.Errorf("no route loaded")
+	}
+	total := s.raw[len(s.raw)-1].Distance
+	if start < 0 || end > total+1e-6 || end-start < 10 {
+		return fmt.Errorf("invalid route slice: %.0f-%.0f m of %.0f m", start, end, total)
+	}
+
+	raw := s.bakedRaw()
+	sliced := []domain.RoutePoint{interpolate(raw, start)}
+	for _, p := range raw {
+		if p.Distance > start && p.Distance < end {
+			sliced = append(sliced, p)
+		}
+	}
+	sliced = append(sliced, interpolate(raw, end))
+	for i := range sliced {
+		sliced[i].Distance -= start
+	}
+
+	var coursePoints []CoursePoint
+	for _, cp := range s.coursePoints {
+		if cp.Distance >= start && cp.Distance <= end {
+			cp.Distance -= start
+			coursePoints = append(coursePoints, cp)
+		}
+	}
+	s.replaceRaw(sliced, coursePoints)
+	return nil
+}
+
+// Reverse turns the route around; grades are recomputed for the new direction.
+func (s *Service) Reverse() error {
+	if len(s.raw) < 2 {
+		return fmt.Errorf("no route loaded")
+	}
+	raw := s.bakedRaw()
+	total := raw[len(raw)-1].Distance
+
+	reversed := make([]domain.RoutePoint, len(raw))
+	for i, p := range raw {
+		p.Distance = total - p.Distance
+		p.Grade = 0
+		reversed[len(raw)-1-i] = p
+	}
+
+	coursePoints := make([]CoursePoint, len(s.coursePoints))
+	for i, cp := range s.coursePoints {
+		cp.Distance = total - cp.Distance
+		coursePoints[len(s.coursePoints)-1-i] = cp
+	}
+	s.replaceRaw(reversed, coursePoints)
+	return nil
+}
+
+// Chain appends other routes after the current one. Distance continues
+// across the joins, including the straight line from one route's end to
+// the next one's start; joins further apart than maxChainGap are rejected.
+func (s *Service) Chain(others ...*Service) error {
+	if len(s.raw) < 2 {
+		return fmt.Errorf("no route loaded")
+	}
+	raw := s.bakedRaw()
+	coursePoints := append([]CoursePoint(nil), s.coursePoints...)
+	missing := s.missingElevation
+
+	for i, o := range others {
+		if len(o.raw) < 2 {
+			return fmt.Errorf("cannot chain an empty route")
+		}
+		last := raw[len(raw)-1]
+		next := o.bakedRaw()
+		gap := gpx.HaversineDistance(last.Latitude, last.Longitude, next[0].Latitude, next[0].Longitude)
+		if gap > maxChainGap {
+			return fmt.Errorf("route %d starts %.0f m from the end of the previous one (max %.0f m)", i+2, gap, maxChainGap)
+		}
+		offset := last.Distance + gap
+		for i, p := range next {
+			p.Distance += offset
+			if i == 0 && p.Distance-last.Distance < 1e-6 {
+				continue // Same point as the end of the previous route
+			}
+			raw = append(raw, p)
+		}
+		for _, cp := range o.coursePoints {
+			cp.Distance += offset
+			coursePoints = append(coursePoints, cp)
+		}
+		missing = missing || o.missingElevation
+	}
+
+	s.replaceRaw(raw, coursePoints)
+	s.missingElevation = missing
+	return nil
+}
+
+// bakedRaw copies the raw points with the surfaces of the processed route,
+// so overrides survive a change of distances.
+func (s *Service) bakedRaw() []domain.RoutePoint {
+	raw := append([]domain.RoutePoint(nil), s.raw...)
+	for i := range raw {
+		raw[i].Surface = s.GetPointAtDistance(raw[i].Distance).Surface
+	}
+	return raw
+}
+
+// replaceRaw makes an edited point set the current route. The result is a new
+// route: it is not tied to the source file's sidecars or DEM comparison.
+func (s *Service) replaceRaw(raw []domain.RoutePoint, coursePoints []CoursePoint) {
+	s.raw = raw
+	s.coursePoints = coursePoints
+	s.sourcePath = ""
+	s.surfaceOverrides = nil
+	s.comparison = nil
+	s.video = nil // Filmed along the original route
+	s.rebuild()
+}
+
+// interpolate returns the route point at a distance between the given points.
+func interpolate(points []domain.RoutePoint, distance float64) domain.RoutePoint {
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if distance <= b.Distance || i == len(points)-1 {
+			ratio := 0.0
+			if seg := b.Distance - a.Distance; seg > 0 {
+				ratio = math.Max(0, math.Min(1, (distance-a.Distance)/seg))
+			}
+			return domain.RoutePoint{
+				Latitude:  lerp(a.Latitude, b.Latitude, ratio),
+				Longitude: lerp(a.Longitude, b.Longitude, ratio),
+				Elevation: lerp(a.Elevation, b.Elevation, ratio),
+				Distance:  distance,
+				Surface:   a.Surface,
+			}
+		}
+	}
+	return points[0]
+}
@@ -0,0 +1,149 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"fmt"
+	"math"
+
+	"argus-cyclist/internal/domain"
+)
+
+// ElevationSource provides terrain elevation (m) for a coordinate, e.g. a DEM.
+type ElevationSource interface {
+	Elevation(lat, lon float64) (float64, error)
+}
+
+// ElevationComparison shows the route profile before and after DEM correction.
+type ElevationComparison struct {
+	Distance          []float64 `json:"distance"`
+	Original          []float64 `json:"original"`
+	Corrected         []float64 `json:"corrected"`
+	OriginalGain      float64   `json:"original_gain"`
+	CorrectedGain     float64   `json:"corrected_gain"`
+	OriginalMaxGrade  float64   `json:"original_max_grade"`
+	CorrectedMaxGrade float64   `json:"corrected_max_grade"`
+	MissingSamples    int       `json:"missing_samples"` // Points without DEM data (original elevation kept)
+}
+
+// SetElevationSource enables DEM correction for the routes loaded next (nil disables it).
+func (s *Service) SetElevationSource(src ElevationSource) {
+	s.elevationSource = src
+}
+
+// GetElevationComparison returns the before/after profile of the last correction (nil if none).
+func (s *Service) GetElevationComparison() *ElevationComparison {
+	return s.comparison
+}
+
+// CorrectElevation replaces the loaded route's elevations with DEM samples
+// and recomputes the grades. Correcting twice starts from the original elevations.
+func (s *Service) CorrectElevation(src ElevationSource) (*ElevationComparison, error) {
+	if len(s.raw) < 2 {
+		return nil, fmt.Errorf("no route loaded")
+	}
+	if s.comparison != nil {
+		s.restoreElevation()
+	}
+	if err := s.applyElevationCorrection(src); err != nil {
+		return nil, err
+	}
+	s.rebuild()
+	s.missingElevation = false
+	return s.comparison, nil
+}
+
+// RestoreElevation puts back the elevations from the route file.
+func (s *Service) RestoreElevation() error {
+	if s.comparison == nil {
+		return fmt.Errorf("the route elevation was not corrected")
+	}
+	s.restoreElevation()
+	s.comparison = nil
+	s.rebuild()
+	return nil
+}
+
+func (s *Service) restoreElevation() {
+	for i := range s.raw {
+		if i < len(s.comparison.Original) {
+			s.raw[i].Elevation = s.comparison.Original[i]
+		}
+	}
+}
+
+// applyElevationCorrection samples the DEM into the raw points. CorrectedMaxGrade
+// is filled by the next rebuild, once grades are smoothed.
+func (s *Service) applyElevationCorrection(src ElevationSource) error {
+	before := processRoute(append([]domain.RoutePoint(nil), s.raw...), s.options)
+	comparison, err := correctElevation(s.raw, src)
+	if err != nil {
+		return err
+	}
+	comparison.OriginalMaxGrade = maxAbsGrade(before)
+	s.comparison = comparison
+	return nil
+}
+
+// correctElevation samples the DEM for every point, before grades are computed.
+func correctElevation(points []domain.RoutePoint, src ElevationSource) (*ElevationComparison, error) {
+	c := &ElevationComparison{
+		Distance:  make([]float64, len(points)),
+		Original:  make([]float64, len(points)),
+		Corrected: make([]float64, len(points)),
+	}
+
+	for i := range points {
+		c.Distance[i] = points[i].Distance
+		c.Original[i] = points[i].Elevation
+		if ele, err := src.Elevation(points[i].Latitude, points[i].Longitude); err == nil {
+			points[i].Elevation = ele
+		} else {
+			c.MissingSamples++
+		}
+		c.Corrected[i] = points[i].Elevation
+	}
+	if c.MissingSamples == len(points) {
+		// Nothing was covered by the DEM: leave the route untouched
+		for i := range points {
+			points[i].Elevation = c.Original[i]
+		}
+		return nil, fmt.Errorf("no DEM tiles cover this route")
+	}
+
+	c.OriginalGain = elevationGain(c.Original)
+	c.CorrectedGain = elevationGain(c.Corrected)
+	return c, nil
+}
+
+func elevationGain(elevations []float64) float64 {
+	gain := 0.0
+	for i := 1; i < len(elevations); i++ {
+		if d := elevations[i] - elevations[i-1]; d > 0 {
+			gain += d
+		}
+	}
+	return gain
+}
+
+func maxAbsGrade(points []domain.RoutePoint) float64 {
+	maxGrade := 0.0
+	for _, p := range points {
+		maxGrade = math.Max(maxGrade, math.Abs(p.Grade))
+	}
+	return maxGrade
+}
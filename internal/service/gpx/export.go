@@ -0,0 +1,110 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"argus-cyclist/internal/domain"
+)
+
+// EncodeGPX writes the route as a single-track GPX. Surfaces are kept as
+// <surface> point extensions, which the loader reads back.
+func EncodeGPX(name string, points []domain.RoutePoint) []byte {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(name))
+
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="Argus Cyclist">
+  <trk>
+    <name>`)
+	sb.WriteString(escaped.String())
+	sb.WriteString(`</name>
+    <trkseg>
+`)
+
+	for _, p := range points {
+		sb.WriteString(fmt.Sprintf(`      <trkpt lat="%.7f" lon="%.7f"><ele>%.2f</ele>`, p.Latitude, p.Longitude, p.Elevation))
+		if p.Surface != "" {
+			sb.WriteString(fmt.Sprintf("<extensions><surface>%s</surface></extensions>", p.Surface))
+		}
+		sb.WriteString("</trkpt>\n")
+	}
+
+	sb.WriteString(`    </trkseg>
+  </trk>
+</gpx>`)
+	return []byte(sb.String())
+}
+
+// EncodeGeoJSON writes the route as a GeoJSON Feature with a 3D LineString.
+func EncodeGeoJSON(name string, points []domain.RoutePoint) ([]byte, error) {
+	coords := make([][3]float64, 0, len(points))
+	for _, p := range points {
+		coords = append(coords, [3]float64{p.Longitude, p.Latitude, p.Elevation})
+	}
+
+	feature := map[string]interface{}{
+		"type": "Feature",
+		"properties": map[string]interface{}{
+			"name":    name,
+			"creator": "Argus Cyclist",
+		},
+		"geometry": map[string]interface{}{
+			"type":        "LineString",
+			"coordinates": coords,
+		},
+	}
+	return json.MarshalIndent(feature, "", "  ")
+}
+
+// EncodeKML writes the route as a KML Placemark with an absolute-altitude LineString.
+func EncodeKML(name string, points []domain.RoutePoint) []byte {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(name))
+
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<kml xmlns="http://www.opengis.net/kml/2.2">
+  <Document>
+    <name>`)
+	sb.WriteString(escaped.String())
+	sb.WriteString(`</name>
+    <Placemark>
+      <name>`)
+	sb.WriteString(escaped.String())
+	sb.WriteString(`</name>
+      <LineString>
+        <altitudeMode>absolute</altitudeMode>
+        <coordinates>
+`)
+
+	for _, p := range points {
+		sb.WriteString(fmt.Sprintf("          %.6f,%.6f,%.2f\n", p.Longitude, p.Latitude, p.Elevation))
+	}
+
+	sb.WriteString(`        </coordinates>
+      </LineString>
+    </Placemark>
+  </Document>
+</kml>`)
+	return []byte(sb.String())
+}
@@ -0,0 +1,452 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/muktihari/fit/decoder"
+	"github.com/muktihari/fit/profile/basetype"
+	"github.com/muktihari/fit/profile/mesgdef"
+	"github.com/muktihari/fit/profile/untyped/mesgnum"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// Route file formats
+const (
+	FormatGPX     = "gpx"
+	FormatTCX     = "tcx"
+	FormatFIT     = "fit"
+	FormatGeoJSON = "geojson"
+	FormatKML     = "kml"
+)
+
+// semicirclesToDegrees converts FIT positions (FIT Standard)
+const semicirclesToDegrees = 180.0 / 2147483648.0
+
+// CoursePoint is a named point along the route (summit, feed zone, turn...),
+// shown to the rider as a point of interest.
+type CoursePoint struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"` // Lowercase TCX PointType / FIT course_point type / GPX waypoint type
+	Kind      string  `json:"kind"` // POI kind: sprint, kom, feed or note
+	Notes     string  `json:"notes"`
+	Distance  float64 `json:"distance"` // Route distance (m)
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+}
+
+// DetectFormat identifies a route file from its content, falling back to the extension.
+func DetectFormat(path string, data []byte) string {
+	// FIT files carry ".FIT" at bytes 8-11 of the header
+	if len(data) >= 12 && string(data[8:12]) == ".FIT" {
+		return FormatFIT
+	}
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	if bytes.Contains(head, []byte("<TrainingCenterDatabase")) {
+		return FormatTCX
+	}
+	if bytes.Contains(head, []byte("<kml")) {
+		return FormatKML
+	}
+	if bytes.Contains(head, []byte("<gpx")) {
+		return FormatGPX
+	}
+	if trimmed := bytes.TrimSpace(head); len(trimmed) > 0 && trimmed[0] == '{' {
+		return FormatGeoJSON
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tcx":
+		return FormatTCX
+	case ".fit":
+		return FormatFIT
+	case ".geojson", ".json":
+		return FormatGeoJSON
+	case ".kml":
+		return FormatKML
+	}
+	return FormatGPX
+}
+
+// parseRouteFile converts any supported format into a GPX track plus its course points.
+func parseRouteFile(path string, data []byte) (*gpx.GPX, []CoursePoint, error) {
+	switch DetectFormat(path, data) {
+	case FormatTCX:
+		return parseTCX(data)
+	case FormatFIT:
+		return parseFITCourse(data)
+	case FormatGeoJSON:
+		g, err := parseGeoJSON(data)
+		return g, nil, err
+	case FormatKML:
+		g, err := parseKML(data)
+		return g, nil, err
+	}
+	g, err := gpx.ParseBytes(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g, waypointCoursePoints(g), nil
+}
+
+// --- TCX ---
+
+type tcxDatabase struct {
+	Courses    []tcxCourse   `xml:"Courses>Course"`
+	Activities []tcxActivity `xml:"Activities>Activity"`
+}
+
+type tcxCourse struct {
+	Name         string           `xml:"Name"`
+	Tracks       []tcxTrack       `xml:"Track"`
+	CoursePoints []tcxCoursePoint `xml:"CoursePoint"`
+}
+
+type tcxActivity struct {
+	Laps []struct {
+		Tracks []tcxTrack `xml:"Track"`
+	} `xml:"Lap"`
+}
+
+type tcxTrack struct {
+	Points []tcxTrackpoint `xml:"Trackpoint"`
+}
+
+type tcxTrackpoint struct {
+	Position *tcxPosition `xml:"Position"`
+	Altitude *float64     `xml:"AltitudeMeters"`
+}
+
+type tcxPosition struct {
+	Latitude  float64 `xml:"LatitudeDegrees"`
+	Longitude float64 `xml:"LongitudeDegrees"`
+}
+
+type tcxCoursePoint struct {
+	Name      string      `xml:"Name"`
+	Position  tcxPosition `xml:"Position"`
+	PointType string      `xml:"PointType"`
+	Notes     string      `xml:"Notes"`
+}
+
+// parseTCX reads the first course of a TCX file (or the laps of an activity).
+func parseTCX(data []byte) (*gpx.GPX, []CoursePoint, error) {
+	var db tcxDatabase
+	if err := xml.Unmarshal(data, &db); err != nil {
+		return nil, nil, fmt.Errorf("invalid TCX file: %w", err)
+	}
+
+	var tracks []tcxTrack
+	var coursePoints []CoursePoint
+	if len(db.Courses) > 0 {
+		tracks = db.Courses[0].Tracks
+		for _, cp := range db.Courses[0].CoursePoints {
+			coursePoints = append(coursePoints, CoursePoint{
+				Name:      cp.Name,
+				Type:      strings.ToLower(cp.PointType),
+				Notes:     cp.Notes,
+				Latitude:  cp.Position.Latitude,
+				Longitude: cp.Position.Longitude,
+			})
+		}
+	} else {
+		for _, act := range db.Activities {
+			for _, lap := range act.Laps {
+				tracks = append(tracks, lap.Tracks...)
+			}
+		}
+	}
+
+	segment := gpx.GPXTrackSegment{}
+	for _, t := range tracks {
+		for _, tp := range t.Points {
+			// Trackpoints without a position only carry sensor data
+			if tp.Position == nil {
+				continue
+			}
+			p := gpx.GPXPoint{Point: gpx.Point{Latitude: tp.Position.Latitude, Longitude: tp.Position.Longitude}}
+			if tp.Altitude != nil {
+				p.Elevation = *gpx.NewNullableFloat64(*tp.Altitude)
+			}
+			segment.Points = append(segment.Points, p)
+		}
+	}
+
+	return singleTrackGPX(segment), coursePoints, nil
+}
+
+// --- FIT course ---
+
+// parseFITCourse reads the records and course points of a FIT course (or activity) file.
+func parseFITCourse(data []byte) (*gpx.GPX, []CoursePoint, error) {
+	fitFile, err := decoder.New(bytes.NewReader(data)).Decode()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid FIT file: %w", err)
+	}
+
+	segment := gpx.GPXTrackSegment{}
+	var coursePoints []CoursePoint
+
+	for i := range fitFile.Messages {
+		msg := &fitFile.Messages[i]
+		switch msg.Num {
+		case mesgnum.Record:
+			rec := mesgdef.NewRecord(msg)
+			if rec.PositionLat == basetype.Sint32Invalid || rec.PositionLong == basetype.Sint32Invalid {
+				continue
+			}
+			p := gpx.GPXPoint{Point: gpx.Point{
+				Latitude:  float64(rec.PositionLat) * semicirclesToDegrees,
+				Longitude: float64(rec.PositionLong) * semicirclesToDegrees,
+			}}
+			// Zero is treated as "not recorded", as in fit.ParseActivity
+			if rec.EnhancedAltitude != basetype.Uint32Invalid && rec.EnhancedAltitude > 0 {
+				p.Elevation = *gpx.NewNullableFloat64(float64(rec.EnhancedAltitude)/5.0 - 500.0)
+			} else if rec.Altitude != basetype.Uint16Invalid && rec.Altitude > 0 {
+				p.Elevation = *gpx.NewNullableFloat64(float64(rec.Altitude)/5.0 - 500.0)
+			}
+			segment.Points = append(segment.Points, p)
+		case mesgnum.CoursePoint:
+			cp := mesgdef.NewCoursePoint(msg)
+			if cp.PositionLat == basetype.Sint32Invalid || cp.PositionLong == basetype.Sint32Invalid {
+				continue
+			}
+			point := CoursePoint{
+				Name:      cp.Name,
+				Type:      cp.Type.String(),
+				Latitude:  float64(cp.PositionLat) * semicirclesToDegrees,
+				Longitude: float64(cp.PositionLong) * semicirclesToDegrees,
+			}
+			if cp.Distance != basetype.Uint32Invalid {
+				point.Distance = float64(cp.Distance) / 100
+			}
+			coursePoints = append(coursePoints, point)
+		}
+	}
+
+	return singleTrackGPX(segment), coursePoints, nil
+}
+
+// --- GeoJSON ---
+
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Features    []geoJSONObject `json:"features"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Geometries  []geoJSONObject `json:"geometries"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// parseGeoJSON reads every LineString / MultiLineString of a GeoJSON document,
+// in order, as one track. Coordinates are [lon, lat] or [lon, lat, elevation].
+func parseGeoJSON(data []byte) (*gpx.GPX, error) {
+	var root geoJSONObject
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON file: %w", err)
+	}
+
+	segment := gpx.GPXTrackSegment{}
+	var walk func(o geoJSONObject) error
+	walk = func(o geoJSONObject) error {
+		switch o.Type {
+		case "FeatureCollection":
+			for _, f := range o.Features {
+				if err := walk(f); err != nil {
+					return err
+				}
+			}
+		case "Feature":
+			if o.Geometry != nil {
+				return walk(*o.Geometry)
+			}
+		case "GeometryCollection":
+			for _, g := range o.Geometries {
+				if err := walk(g); err != nil {
+					return err
+				}
+			}
+		case "LineString":
+			var line [][]float64
+			if err := json.Unmarshal(o.Coordinates, &line); err != nil {
+				return fmt.Errorf("invalid LineString: %w", err)
+			}
+			segment.Points = append(segment.Points, positionsToPoints(line)...)
+		case "MultiLineString":
+			var lines [][][]float64
+			if err := json.Unmarshal(o.Coordinates, &lines); err != nil {
+				return fmt.Errorf("invalid MultiLineString: %w", err)
+			}
+			for _, line := range lines {
+				segment.Points = append(segment.Points, positionsToPoints(line)...)
+			}
+		}
+		return nil
+	}
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+
+	return singleTrackGPX(segment), nil
+}
+
+// positionsToPoints converts [lon, lat, (ele)] positions (GeoJSON and KML order).
+func positionsToPoints(positions [][]float64) []gpx.GPXPoint {
+	var points []gpx.GPXPoint
+	for _, c := range positions {
+		if len(c) < 2 {
+			continue
+		}
+		p := gpx.GPXPoint{Point: gpx.Point{Latitude: c[1], Longitude: c[0]}}
+		if len(c) >= 3 {
+			p.Elevation = *gpx.NewNullableFloat64(c[2])
+		}
+		points = append(points, p)
+	}
+	return points
+}
+
+// --- KML ---
+
+// parseKML reads every <LineString> and <gx:Track> of a KML document, in order, as one track.
+func parseKML(data []byte) (*gpx.GPX, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	segment := gpx.GPXTrackSegment{}
+
+	inLineString := false
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid KML file: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "LineString" {
+				inLineString = true
+			}
+			text.Reset()
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			switch {
+			case t.Name.Local == "LineString":
+				inLineString = false
+			case t.Name.Local == "coordinates" && inLineString:
+				// "lon,lat[,alt]" tuples separated by whitespace
+				var line [][]float64
+				for _, tuple := range strings.Fields(text.String()) {
+					line = append(line, parseFloats(strings.Split(tuple, ",")))
+				}
+				segment.Points = append(segment.Points, positionsToPoints(line)...)
+			case t.Name.Local == "coord":
+				// gx:Track: "lon lat alt"
+				segment.Points = append(segment.Points, positionsToPoints([][]float64{parseFloats(strings.Fields(text.String()))})...)
+			}
+			text.Reset()
+		}
+	}
+
+	return singleTrackGPX(segment), nil
+}
+
+// parseFloats parses values until the first one that is not a number.
+func parseFloats(fields []string) []float64 {
+	var values []float64
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			break
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func singleTrackGPX(segment gpx.GPXTrackSegment) *gpx.GPX {
+	return &gpx.GPX{Tracks: []gpx.GPXTrack{{Segments: []gpx.GPXTrackSegment{segment}}}}
+}
+
+// locateCoursePoints places the course points of a route file, in file order:
+// by the file's distance when it has one (FIT), otherwise by position.
+func (s *Service) locateCoursePoints(points []CoursePoint) []CoursePoint {
+	return s.placeCoursePoints(points)
+}
+
+// coursePointSnap is how close (m) the route must pass to a course point
+// for that pass to be taken as the point's place.
+const coursePointSnap = 50.0
+
+// NearestDistanceAfter returns the route distance of the first pass, from
+// the given distance on, within coursePointSnap of a coordinate, so points
+// on loops and out-and-backs land on the right pass. Without such a pass it
+// falls back to the nearest route point overall.
+func (s *Service) NearestDistanceAfter(lat, lon, from float64) float64 {
+	distance, best := 0.0, math.MaxFloat64
+	for _, rp := range s.points {
+		if rp.Distance < from {
+			continue
+		}
+		d := gpx.HaversineDistance(lat, lon, rp.Latitude, rp.Longitude)
+		if d < best {
+			best = d
+			distance = rp.Distance
+		} else if best <= coursePointSnap && d > coursePointSnap {
+			// Left the first pass near the point
+			return distance
+		}
+	}
+	if best <= coursePointSnap {
+		return distance
+	}
+	distance, _ = s.NearestDistance(lat, lon)
+	return distance
+}
+
+// NearestDistance returns the route distance of the point closest to a
+// coordinate, and how far (m) the coordinate is from it.
+func (s *Service) NearestDistance(lat, lon float64) (float64, float64) {
+	distance, best := 0.0, math.MaxFloat64
+	for _, rp := range s.points {
+		d := gpx.HaversineDistance(lat, lon, rp.Latitude, rp.Longitude)
+		if d < best {
+			best = d
+			distance = rp.Distance
+		}
+	}
+	return distance, best
+}
+
+// GetCoursePoints returns the course points of the loaded route (TCX/FIT courses).
+func (s *Service) GetCoursePoints() []CoursePoint {
+	return s.coursePoints
+}
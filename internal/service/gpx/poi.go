@@ -0,0 +1,157 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// Kinds of points of interest (course points) shown and announced during a ride
+const (
+	POISprint = "sprint" // Sprint line
+	POIKOM    = "kom"    // KOM banner / summit
+	POIFeed   = "feed"   // Feed zone, water, aid station
+	POINote   = "note"   // Anything else worth a message
+)
+
+// poiKeywords map words of TCX/FIT course point types and GPX waypoint
+// types/symbols/names to a POI kind, checked in order.
+var poiKeywords = []struct {
+	word string
+	kind string
+}{
+	{"sprint", POISprint},
+	{"kom", POIKOM},
+	{"summit", POIKOM},
+	{"category", POIKOM}, // fourth_category ... hors_category, "4th Category"
+	{"climb", POIKOM},
+	{"feed", POIFeed},
+	{"food", POIFeed},
+	{"water", POIFeed},
+	{"drink", POIFeed},
+	{"aid", POIFeed}, // aid_station
+	{"energy", POIFeed},
+}
+
+// POIKind classifies a course point type or waypoint label.
+func POIKind(label string) string {
+	label = strings.ToLower(label)
+	switch label {
+	case POISprint, POIKOM, POIFeed, POINote:
+		return label
+	}
+	for _, k := range poiKeywords {
+		if strings.Contains(label, k.word) {
+			return k.kind
+		}
+	}
+	return POINote
+}
+
+// waypointCoursePoints turns GPX waypoints into course points.
+func waypointCoursePoints(g *gpx.GPX) []CoursePoint {
+	var points []CoursePoint
+	for _, w := range g.Waypoints {
+		notes := w.Comment
+		if notes == "" {
+			notes = w.Description
+		}
+		points = append(points, CoursePoint{
+			Name:      w.Name,
+			Type:      strings.ToLower(w.Type),
+			Kind:      POIKind(strings.Join([]string{w.Type, w.Symbol, w.Name}, " ")),
+			Notes:     notes,
+			Latitude:  w.Point.Latitude,
+			Longitude: w.Point.Longitude,
+		})
+	}
+	return points
+}
+
+// poiSidecarPath is the POI file of a route ("stage.gpx" -> "stage.pois.json").
+func poiSidecarPath(routePath string) string {
+	return strings.TrimSuffix(routePath, filepath.Ext(routePath)) + ".pois.json"
+}
+
+// LoadCoursePoints reads a JSON list of course points for the loaded route.
+// Points without a distance are placed at the route point nearest to lat/lon.
+func (s *Service) LoadCoursePoints(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var points []CoursePoint
+	if err := json.Unmarshal(data, &points); err != nil {
+		return fmt.Errorf("invalid points of interest file: %w", err)
+	}
+	s.coursePoints = s.placeCoursePoints(points)
+	return nil
+}
+
+// SetCoursePoints replaces the points of interest of the loaded route. When
+// the route came from a file they are saved next to it, and that file then
+// takes the place of the route's own waypoints on the next load.
+func (s *Service) SetCoursePoints(points []CoursePoint) error {
+	if len(s.points) < 2 {
+		return fmt.Errorf("no route loaded")
+	}
+	s.coursePoints = s.placeCoursePoints(points)
+
+	if s.sourcePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.coursePoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(poiSidecarPath(s.sourcePath), data, 0644)
+}
+
+// placeCoursePoints fills in kinds and missing distances, sorted along the route.
+// Points without a distance are searched for from the previous point on, as
+// lists are in route order.
+func (s *Service) placeCoursePoints(points []CoursePoint) []CoursePoint {
+	placed := make([]CoursePoint, 0, len(points))
+	total := s.GetTotalDistance()
+	from := 0.0
+	for _, cp := range points {
+		if cp.Distance == 0 && (cp.Latitude != 0 || cp.Longitude != 0) {
+			cp.Distance = s.NearestDistanceAfter(cp.Latitude, cp.Longitude, from)
+		} else if cp.Latitude == 0 && cp.Longitude == 0 {
+			p := s.GetPointAtDistance(cp.Distance)
+			cp.Latitude, cp.Longitude = p.Latitude, p.Longitude
+		}
+		cp.Distance = math.Min(cp.Distance, total)
+		from = cp.Distance
+		if cp.Kind == "" {
+			cp.Kind = POIKind(cp.Type + " " + cp.Name)
+		} else {
+			cp.Kind = POIKind(cp.Kind)
+		}
+		placed = append(placed, cp)
+	}
+	sort.SliceStable(placed, func(i, j int) bool { return placed[i].Distance < placed[j].Distance })
+	return placed
+}
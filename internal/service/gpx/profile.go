@@ -0,0 +1,238 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"fmt"
+	"math"
+
+	"argus-cyclist/internal/domain"
+)
+
+// Elevation profile downsampling methods
+const (
+	ProfileLTTB = "lttb" // Largest-Triangle-Three-Buckets: keeps the shape (peaks, dips)
+	ProfileStep = "step" // Fixed distance step
+)
+
+const defaultProfilePoints = 500
+
+// gradeBands colour the profile like the HUD grade readout, steepest first.
+var gradeBands = []struct {
+	min   float64 // % grade from which the band applies
+	name  string
+	color string
+}{
+	{12, "extreme", "#ef4444"},
+	{9, "steep", "#f472b6"},
+	{6, "hard", "#fbbf24"},
+	{3, "moderate", "#10b981"},
+	{-3, "flat", "#e0e6ed"},
+	{math.Inf(-1), "descent", "#60a5fa"},
+}
+
+// ProfileOptions selects the part of the route and the resolution of an elevation profile.
+type ProfileOptions struct {
+	Points int     `json:"points"` // Maximum points returned (0 = 500)
+	Method string  `json:"method"` // "lttb" (default) or "step"
+	From   float64 `json:"from"`   // Route position (m) where the profile starts
+	To     float64 `json:"to"`     // Route position (m) where it ends (0 = route end)
+}
+
+// ProfilePoint is one point of the elevation profile.
+type ProfilePoint struct {
+	Distance  float64 `json:"distance"`  // Route position (m)
+	Elevation float64 `json:"elevation"` // m
+	Grade     float64 `json:"grade"`     // % from the previous profile point
+	Band      string  `json:"band"`
+}
+
+// GradeBand is a stretch of the profile within one grade band.
+type GradeBand struct {
+	From  float64 `json:"from"` // m
+	To    float64 `json:"to"`   // m
+	Grade float64 `json:"grade"`
+	Band  string  `json:"band"`
+	Color string  `json:"color"`
+}
+
+// ElevationProfile is a distance-indexed, downsampled elevation profile.
+type ElevationProfile struct {
+	From         float64        `json:"from"`
+	To           float64        `json:"to"`
+	MinElevation float64        `json:"min_elevation"`
+	MaxElevation float64        `json:"max_elevation"`
+	Points       []ProfilePoint `json:"points"`
+	Bands        []GradeBand    `json:"bands"`
+}
+
+// GradeBandOf returns the band name and colour for a grade (%).
+func GradeBandOf(grade float64) (string, string) {
+	for _, b := range gradeBands {
+		if grade >= b.min {
+			return b.name, b.color
+		}
+	}
+	last := gradeBands[len(gradeBands)-1]
+	return last.name, last.color
+}
+
+// ElevationProfile returns the elevation profile of (part of) the loaded route.
+func (s *Service) ElevationProfile(opts ProfileOptions) (ElevationProfile, error) {
+	if len(s.points) < 2 {
+		return ElevationProfile{}, fmt.Errorf("no route loaded")
+	}
+	total := s.GetTotalDistance()
+	if opts.To <= 0 || opts.To > total {
+		opts.To = total
+	}
+	opts.From = math.Max(0, opts.From)
+	if opts.From >= opts.To {
+		return ElevationProfile{}, fmt.Errorf("invalid profile range: %.0f-%.0f m", opts.From, opts.To)
+	}
+	if opts.Points <= 0 {
+		opts.Points = defaultProfilePoints
+	}
+	opts.Points = max(opts.Points, 3)
+
+	// Route points within the range, with interpolated ends
+	section := []domain.RoutePoint{interpolate(s.points, opts.From)}
+	for _, p := range s.points {
+		if p.Distance > opts.From && p.Distance < opts.To {
+			section = append(section, p)
+		}
+	}
+	section = append(section, interpolate(s.points, opts.To))
+
+	var sampled []domain.RoutePoint
+	switch opts.Method {
+	case ProfileStep:
+		sampled = stepSample(s.points, opts.From, opts.To, opts.Points)
+	case "", ProfileLTTB:
+		sampled = lttb(section, opts.Points)
+	default:
+		return ElevationProfile{}, fmt.Errorf("unknown profile method %q", opts.Method)
+	}
+
+	profile := ElevationProfile{
+		From:         opts.From,
+		To:           opts.To,
+		MinElevation: math.Inf(1),
+		MaxElevation: math.Inf(-1),
+		Points:       make([]ProfilePoint, len(sampled)),
+	}
+	for i, p := range sampled {
+		profile.MinElevation = math.Min(profile.MinElevation, p.Elevation)
+		profile.MaxElevation = math.Max(profile.MaxElevation, p.Elevation)
+		a, b := sampled[max(i-1, 0)], sampled[max(i, 1)]
+		grade := 0.0
+		if d := b.Distance - a.Distance; d > 0 {
+			grade = clampGrade((b.Elevation - a.Elevation) / d * 100)
+		}
+		band, _ := GradeBandOf(grade)
+		profile.Points[i] = ProfilePoint{Distance: p.Distance, Elevation: p.Elevation, Grade: grade, Band: band}
+	}
+	profile.Bands = gradeBandRanges(section, (opts.To-opts.From)/float64(opts.Points))
+	return profile, nil
+}
+
+// stepSample samples the route every (to-from)/(n-1) metres.
+func stepSample(points []domain.RoutePoint, from, to float64, n int) []domain.RoutePoint {
+	sampled := make([]domain.RoutePoint, n)
+	step := (to - from) / float64(n-1)
+	for i := range sampled {
+		sampled[i] = interpolate(points, from+float64(i)*step)
+	}
+	return sampled
+}
+
+// lttb downsamples to n points with Largest-Triangle-Three-Buckets over
+// (distance, elevation), keeping the first and last point.
+func lttb(points []domain.RoutePoint, n int) []domain.RoutePoint {
+	if len(points) <= n {
+		return points
+	}
+	sampled := make([]domain.RoutePoint, 0, n)
+	sampled = append(sampled, points[0])
+
+	bucket := float64(len(points)-2) / float64(n-2)
+	a := 0
+	for i := 0; i < n-2; i++ {
+		start := int(float64(i)*bucket) + 1
+		end := int(float64(i+1)*bucket) + 1
+
+		// Average of the next bucket (the last point for the last bucket)
+		nextStart, nextEnd := end, min(int(float64(i+2)*bucket)+1, len(points))
+		if i == n-3 {
+			nextStart, nextEnd = len(points)-1, len(points)
+		}
+		var avgX, avgY float64
+		for _, p := range points[nextStart:nextEnd] {
+			avgX += p.Distance
+			avgY += p.Elevation
+		}
+		avgX /= float64(nextEnd - nextStart)
+		avgY /= float64(nextEnd - nextStart)
+
+		best, bestArea := start, -1.0
+		for j := start; j < end; j++ {
+			area := math.Abs((points[a].Distance-avgX)*(points[j].Elevation-points[a].Elevation) -
+				(points[a].Distance-points[j].Distance)*(avgY-points[a].Elevation))
+			if area > bestArea {
+				best, bestArea = j, area
+			}
+		}
+		sampled = append(sampled, points[best])
+		a = best
+	}
+	return append(sampled, points[len(points)-1])
+}
+
+// gradeBandRanges splits the points into grade bands; bands shorter than
+// minLength are merged into the previous one so the colouring stays readable.
+func gradeBandRanges(points []domain.RoutePoint, minLength float64) []GradeBand {
+	var bands []GradeBand
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		if b.Distance <= a.Distance {
+			continue
+		}
+		name, color := GradeBandOf(a.Grade)
+		if n := len(bands); n > 0 && bands[n-1].Band == name {
+			bands[n-1].To = b.Distance
+			continue
+		}
+		bands = append(bands, GradeBand{From: a.Distance, To: b.Distance, Band: name, Color: color})
+	}
+
+	// Absorb short bands into their predecessor and join equal neighbours
+	merged := bands[:0]
+	for _, band := range bands {
+		if n := len(merged); n > 0 && (merged[n-1].Band == band.Band || band.To-band.From < minLength) {
+			merged[n-1].To = band.To
+			continue
+		}
+		merged = append(merged, band)
+	}
+
+	// Average grade over each band
+	for i := range merged {
+		start, end := interpolate(points, merged[i].From), interpolate(points, merged[i].To)
+		merged[i].Grade = (end.Elevation - start.Elevation) / (merged[i].To - merged[i].From) * 100
+	}
+	return merged
+}
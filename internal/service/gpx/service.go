@@ -17,8 +17,10 @@
 package gpx
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"os"
 	"strings"
 
 	"argus-cyclist/internal/domain"
@@ -27,22 +29,81 @@ import (
 )
 
 type Service struct {
-	points []domain.RoutePoint
+	points           []domain.RoutePoint
+	coursePoints     []CoursePoint
+	missingElevation bool // The source had no elevation data (flat profile)
+
+	// Unsmoothed points (after DEM correction) and how they become s.points
+	raw              []domain.RoutePoint
+	options          ProcessingOptions
+	sourcePath       string
+	surfaceOverrides []SurfaceOverride
+
+	// Detected on the processed points, forwards and for the reversed route
+	climbs        []Climb
+	reverseClimbs []Climb
+
+	// Ride-along video of the route (nil = none)
+	video *RouteVideo
+
+	// DEM correction applied while loading (nil = use the file's elevations)
+	elevationSource ElevationSource
+	comparison      *ElevationComparison
 }
 
 func NewService() *Service {
 	return &Service{
-		points: []domain.RoutePoint{},
+		points:  []domain.RoutePoint{},
+		options: DefaultProcessingOptions(),
 	}
 }
 
+// LoadAndProcess loads a GPX route, TCX course or FIT course (the format is
+// detected from the content) into the route pipeline.
 func (s *Service) LoadAndProcess(filepath string) ([]domain.RoutePoint, error) {
-	gpxFile, err := gpx.ParseFile(filepath)
+	data, err := os.ReadFile(filepath)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.processParsedGPX(gpxFile)
+	gpxFile, coursePoints, err := parseRouteFile(filepath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	s.options = loadProcessingOptions(filepath)
+	if _, err := s.processParsedGPX(gpxFile); err != nil {
+		return nil, err
+	}
+	s.sourcePath = filepath
+	s.coursePoints = s.locateCoursePoints(coursePoints)
+	s.video = nil
+	pois := poiSidecarPath(filepath)
+	if _, statErr := os.Stat(pois); statErr == nil {
+		if err := s.LoadCoursePoints(pois); err != nil {
+			fmt.Printf("[GPX] Ignoring points of interest %s: %v\n", pois, err)
+		}
+	}
+
+	sidecar := surfaceSidecarPath(filepath)
+	if _, statErr := os.Stat(sidecar); statErr == nil {
+		if err := s.LoadSurfaceOverrides(sidecar); err != nil {
+			fmt.Printf("[GPX] Ignoring surface overrides %s: %v\n", sidecar, err)
+		}
+	}
+	videoSidecar := videoSidecarPath(filepath)
+	if _, statErr := os.Stat(videoSidecar); statErr == nil {
+		video, err := ReadVideoFile(videoSidecar)
+		if err == nil {
+			err = s.checkVideo(video)
+		}
+		if err != nil {
+			fmt.Printf("[GPX] Ignoring video %s: %v\n", videoSidecar, err)
+		} else {
+			s.video = video
+		}
+	}
+	return s.points, nil
 }
 
 func (s *Service) LoadAndProcessContent(content string) ([]domain.RoutePoint, error) {
@@ -51,7 +112,14 @@ func (s *Service) LoadAndProcessContent(content string) ([]domain.RoutePoint, er
 		return nil, err
 	}
 
-	return s.processParsedGPX(gpxFile)
+	s.options = DefaultProcessingOptions()
+	points, err := s.processParsedGPX(gpxFile)
+	if err == nil {
+		s.sourcePath = ""
+		s.coursePoints = s.locateCoursePoints(waypointCoursePoints(gpxFile))
+		s.video = nil
+	}
+	return points, err
 }
 
 func (s *Service) processParsedGPX(gpxFile *gpx.GPX) ([]domain.RoutePoint, error) {
@@ -64,6 +132,9 @@ func (s *Service) processParsedGPX(gpxFile *gpx.GPX) ([]domain.RoutePoint, error
 
 	var previousPoint *gpx.GPXPoint
 	firstPoint := true
+	hasElevation := false
+	// A surface tag applies until the next tagged point.
+	surface := ""
 
 	processPoint := func(p *gpx.GPXPoint) {
 		distDelta := 0.0
@@ -73,12 +144,21 @@ func (s *Service) processParsedGPX(gpxFile *gpx.GPX) ([]domain.RoutePoint, error
 
 		totalDist += distDelta
 
+		if p.Elevation.NotNull() {
+			hasElevation = true
+		}
+
+		if tagged := surfaceFromExtensions(p.Extensions); tagged != "" {
+			surface = tagged
+		}
+
 		processedPoints = append(processedPoints, domain.RoutePoint{
 			Latitude:  p.Point.Latitude,
 			Longitude: p.Point.Longitude,
 			Elevation: p.Elevation.Value(),
 			Distance:  totalDist,
 			Grade:     0,
+			Surface:   surface,
 		})
 
 		pCopy := *p
@@ -87,6 +167,7 @@ func (s *Service) processParsedGPX(gpxFile *gpx.GPX) ([]domain.RoutePoint, error
 	}
 
 	for _, track := range gpxFile.Tracks {
+		surface = surfaceFromExtensions(track.Extensions)
 		for _, segment := range track.Segments {
 			for i := range segment.Points {
 				processPoint(&segment.Points[i])
@@ -103,13 +184,70 @@ func (s *Service) processParsedGPX(gpxFile *gpx.GPX) ([]domain.RoutePoint, error
 	}
 
 	if len(processedPoints) < 2 {
-		return nil, fmt.Errorf("the GPX file does not contain valid GPS points")
+		return nil, fmt.Errorf("the route file does not contain valid GPS points")
 	}
 
-	s.points = smoothGrades(processedPoints)
+	s.raw = processedPoints
+	s.surfaceOverrides = nil
+	s.comparison = nil
+	if s.elevationSource != nil {
+		// DEM elevations replace the file's before grades are computed
+		if err := s.applyElevationCorrection(s.elevationSource); err != nil {
+			fmt.Printf("[GPX] Elevation correction skipped: %v\n", err)
+		} else {
+			hasElevation = true
+		}
+	}
+
+	s.rebuild()
+	s.coursePoints = nil
+	s.missingElevation = !hasElevation
 	return s.points, nil
 }
 
+// rebuild processes the raw points with the route's options and re-applies
+// any surface overrides, which are stored by distance.
+func (s *Service) rebuild() {
+	s.points = processRoute(append([]domain.RoutePoint(nil), s.raw...), s.options)
+	applySurfaceOverrides(s.points, s.surfaceOverrides)
+	s.updateClimbs()
+	if s.comparison != nil {
+		s.comparison.CorrectedMaxGrade = maxAbsGrade(s.points)
+	}
+}
+
+// SourcePath is the file the route was loaded from ("" for generated or edited routes).
+func (s *Service) SourcePath() string {
+	return s.sourcePath
+}
+
+// GetProcessingOptions returns how the loaded route was resampled and smoothed.
+func (s *Service) GetProcessingOptions() ProcessingOptions {
+	return s.options
+}
+
+// SetProcessingOptions re-processes the loaded route. When the route came from
+// a file the options are saved next to it, so they apply the next time it loads.
+func (s *Service) SetProcessingOptions(opts ProcessingOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if len(s.raw) < 2 {
+		return fmt.Errorf("no route loaded")
+	}
+	s.options = opts
+	s.rebuild()
+
+	if s.sourcePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(opts, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(processingSidecarPath(s.sourcePath), data, 0644)
+}
+
 func (s *Service) GetAllPoints() []domain.RoutePoint {
 	return s.points
 }
@@ -121,8 +259,24 @@ func (s *Service) GetTotalDistance() float64 {
 	return s.points[len(s.points)-1].Distance
 }
 
+// SetPoints replaces the route with already processed points (e.g. a generated route).
 func (s *Service) SetPoints(points []domain.RoutePoint) {
 	s.points = points
+	s.raw = append([]domain.RoutePoint(nil), points...)
+	s.options = DefaultProcessingOptions()
+	s.sourcePath = ""
+	s.surfaceOverrides = nil
+	s.updateClimbs()
+	s.coursePoints = nil
+	s.video = nil
+	s.missingElevation = false
+	s.comparison = nil
+}
+
+// ElevationMissing reports whether the loaded route came without elevation data,
+// so its profile is flat and should be corrected before riding.
+func (s *Service) ElevationMissing() bool {
+	return s.missingElevation
 }
 
 func (s *Service) GetPointAtDistance(distanceMeter float64) domain.RoutePoint {
@@ -170,9 +324,41 @@ func (s *Service) GetPointAtDistance(distanceMeter float64) domain.RoutePoint {
 		Elevation: lerp(pPrev.Elevation, pNext.Elevation, ratio),
 		Grade:     pPrev.Grade,
 		Distance:  distanceMeter,
+		Surface:   pPrev.Surface,
 	}
 }
 
+// GetHeadingAtDistance returns the direction of travel (degrees, 0 = north,
+// clockwise) of the route segment containing the given distance.
+func (s *Service) GetHeadingAtDistance(distanceMeter float64) float64 {
+	if len(s.points) < 2 {
+		return 0
+	}
+
+	idx := 1
+	for idx < len(s.points)-1 && s.points[idx].Distance < distanceMeter {
+		idx++
+	}
+	// Skip zero-length segments (duplicated track points) backwards.
+	prev := idx - 1
+	for prev > 0 && s.points[prev].Latitude == s.points[idx].Latitude && s.points[prev].Longitude == s.points[idx].Longitude {
+		prev--
+	}
+
+	return bearing(s.points[prev].Latitude, s.points[prev].Longitude, s.points[idx].Latitude, s.points[idx].Longitude)
+}
+
+// bearing computes the initial great-circle bearing between two coordinates.
+func bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := math.Pi / 180.0
+	phi1, phi2 := lat1*toRad, lat2*toRad
+	dLon := (lon2 - lon1) * toRad
+
+	y := math.Sin(dLon) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)/toRad+360.0, 360.0)
+}
+
 // lerp performs linear interpolation between two values.
 func lerp(start, end, ratio float64) float64 {
 	return start + ratio*(end-start)
@@ -197,13 +383,7 @@ func smoothGrades(points []domain.RoutePoint) []domain.RoutePoint {
 
 		if distDelta > 10.0 {
 			grade := (elevDelta / distDelta) * 100
-			if grade > 25 {
-				grade = 25
-			}
-			if grade < -25 {
-				grade = -25
-			}
-			points[i].Grade = grade
+			points[i].Grade = clampGrade(grade)
 		} else {
 			points[i].Grade = 0
 		}
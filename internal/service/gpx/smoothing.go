@@ -0,0 +1,412 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"argus-cyclist/internal/domain"
+)
+
+// Grade smoothing algorithms
+const (
+	SmoothingPoints        = "points"         // Legacy: grade over ±5 points
+	SmoothingMovingAverage = "moving_average" // Elevation averaged over a distance window
+	SmoothingSavitzkyGolay = "savitzky_golay" // Local quadratic fit over a distance window
+	SmoothingKalman        = "kalman"         // Elevation/slope Kalman filter with RTS smoothing
+)
+
+const (
+	gradeLimit = 25.0
+
+	defaultResampleStep     = 10.0 // m
+	minResampleStep         = 1.0  // m
+	defaultSmoothingWindow  = 50.0 // m
+	defaultOutlierThreshold = 5.0  // m
+	outlierHalfWindow       = 25.0 // m each side of the point
+
+	// Kalman model: elevation noise (m) and how fast the slope may change (per √m)
+	kalmanElevationSigma = 3.0
+	kalmanSlopeSigma     = 0.002
+)
+
+// ProcessingOptions control how a route's points become the ridden profile.
+type ProcessingOptions struct {
+	ResampleStep     float64 `json:"resample_step"`     // m between points, 0 = keep the file's points
+	Smoothing        string  `json:"smoothing"`         // Smoothing* constant
+	Window           float64 `json:"window"`            // m, for moving average and Savitzky–Golay
+	RemoveOutliers   bool    `json:"remove_outliers"`   // Replace elevation spikes with the local median
+	OutlierThreshold float64 `json:"outlier_threshold"` // Minimum deviation (m) for a spike
+}
+
+// DefaultProcessingOptions resamples to a fixed step and smooths over a distance
+// window, so grades do not depend on how densely the file was recorded.
+func DefaultProcessingOptions() ProcessingOptions {
+	return ProcessingOptions{
+		ResampleStep:     defaultResampleStep,
+		Smoothing:        SmoothingMovingAverage,
+		Window:           defaultSmoothingWindow,
+		OutlierThreshold: defaultOutlierThreshold,
+	}
+}
+
+// Validate fills defaults and rejects unknown algorithms.
+func (o *ProcessingOptions) Validate() error {
+	if o.Smoothing == "" {
+		o.Smoothing = SmoothingMovingAverage
+	}
+	switch o.Smoothing {
+	case SmoothingPoints, SmoothingMovingAverage, SmoothingSavitzkyGolay, SmoothingKalman:
+	default:
+		return fmt.Errorf("unknown smoothing: %s", o.Smoothing)
+	}
+	if o.ResampleStep < 0 || o.Window < 0 || o.OutlierThreshold < 0 {
+		return fmt.Errorf("processing options cannot be negative")
+	}
+	if o.ResampleStep > 0 && o.ResampleStep < minResampleStep {
+		return fmt.Errorf("resample step must be at least %.0f m", minResampleStep)
+	}
+	if o.Window == 0 {
+		o.Window = defaultSmoothingWindow
+	}
+	if o.OutlierThreshold == 0 {
+		o.OutlierThreshold = defaultOutlierThreshold
+	}
+	return nil
+}
+
+// processingSidecarPath is the per-route options file ("stage.gpx" -> "stage.processing.json").
+func processingSidecarPath(routePath string) string {
+	return strings.TrimSuffix(routePath, filepath.Ext(routePath)) + ".processing.json"
+}
+
+// loadProcessingOptions reads the route's options file, or the defaults if there is none.
+func loadProcessingOptions(routePath string) ProcessingOptions {
+	opts := DefaultProcessingOptions()
+	data, err := os.ReadFile(processingSidecarPath(routePath))
+	if err != nil {
+		return opts
+	}
+	if err := json.Unmarshal(data, &opts); err != nil {
+		fmt.Printf("[GPX] Ignoring processing options for %s: %v\n", routePath, err)
+		return DefaultProcessingOptions()
+	}
+	if err := opts.Validate(); err != nil {
+		fmt.Printf("[GPX] Ignoring processing options for %s: %v\n", routePath, err)
+		return DefaultProcessingOptions()
+	}
+	return opts
+}
+
+// processRoute turns raw points into the ridden profile: outlier removal,
+// resampling, then elevation/grade smoothing. points is modified in place.
+func processRoute(points []domain.RoutePoint, opts ProcessingOptions) []domain.RoutePoint {
+	if opts.RemoveOutliers {
+		removeElevationOutliers(points, opts.OutlierThreshold)
+	}
+	if opts.ResampleStep > 0 {
+		points = resample(points, opts.ResampleStep)
+	}
+
+	switch opts.Smoothing {
+	case SmoothingMovingAverage:
+		movingAverage(points, opts.Window)
+		gradesFromElevation(points)
+	case SmoothingSavitzkyGolay:
+		savitzkyGolay(points, opts.Window)
+	case SmoothingKalman:
+		kalmanSmooth(points)
+	default:
+		points = smoothGrades(points)
+	}
+	return points
+}
+
+// removeElevationOutliers is a Hampel filter: points deviating from the median
+// of their ±25 m neighbourhood by more than max(threshold, 3 scaled MADs)
+// are replaced by that median.
+func removeElevationOutliers(points []domain.RoutePoint, threshold float64) {
+	original := make([]float64, len(points))
+	for i, p := range points {
+		original[i] = p.Elevation
+	}
+
+	for i := range points {
+		lo, hi := i, i
+		for lo > 0 && (points[i].Distance-points[lo-1].Distance <= outlierHalfWindow || i-lo < 2) {
+			lo--
+		}
+		for hi < len(points)-1 && (points[hi+1].Distance-points[i].Distance <= outlierHalfWindow || hi-i < 2) {
+			hi++
+		}
+
+		window := append([]float64(nil), original[lo:hi+1]...)
+		med := median(window)
+		for j := range window {
+			window[j] = math.Abs(window[j] - med)
+		}
+		limit := math.Max(threshold, 3*1.4826*median(window))
+
+		if math.Abs(original[i]-med) > limit {
+			points[i].Elevation = med
+		}
+	}
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// resample places points every step metres along the route (plus the last point).
+func resample(points []domain.RoutePoint, step float64) []domain.RoutePoint {
+	if len(points) < 2 {
+		return points
+	}
+	total := points[len(points)-1].Distance
+	out := make([]domain.RoutePoint, 0, int(total/step)+2)
+
+	j := 0
+	for d := 0.0; d < total; d += step {
+		for j < len(points)-2 && points[j+1].Distance <= d {
+			j++
+		}
+		a, b := points[j], points[j+1]
+		ratio := 0.0
+		if seg := b.Distance - a.Distance; seg > 0 {
+			ratio = (d - a.Distance) / seg
+		}
+		out = append(out, domain.RoutePoint{
+			Latitude:  lerp(a.Latitude, b.Latitude, ratio),
+			Longitude: lerp(a.Longitude, b.Longitude, ratio),
+			Elevation: lerp(a.Elevation, b.Elevation, ratio),
+			Distance:  d,
+			Surface:   a.Surface,
+		})
+	}
+	out = append(out, points[len(points)-1])
+	return out
+}
+
+// movingAverage replaces every elevation with the mean over ±window/2 metres.
+func movingAverage(points []domain.RoutePoint, window float64) {
+	half := window / 2
+	original := make([]float64, len(points))
+	for i, p := range points {
+		original[i] = p.Elevation
+	}
+
+	lo, hi := 0, 0
+	sum := 0.0
+	for i := range points {
+		for hi < len(points) && points[hi].Distance <= points[i].Distance+half {
+			sum += original[hi]
+			hi++
+		}
+		for points[lo].Distance < points[i].Distance-half {
+			sum -= original[lo]
+			lo++
+		}
+		points[i].Elevation = sum / float64(hi-lo)
+	}
+}
+
+// gradesFromElevation sets each grade from the central difference of the (smoothed) elevation.
+func gradesFromElevation(points []domain.RoutePoint) {
+	for i := range points {
+		a, b := points[max(i-1, 0)], points[min(i+1, len(points)-1)]
+		points[i].Grade = 0
+		if dist := b.Distance - a.Distance; dist > 0 {
+			points[i].Grade = clampGrade((b.Elevation - a.Elevation) / dist * 100)
+		}
+	}
+}
+
+// savitzkyGolay fits a quadratic over ±window/2 metres around every point
+// (least squares on the real distances, so uneven spacing is fine). The fit
+// gives the smoothed elevation and its derivative gives the grade.
+func savitzkyGolay(points []domain.RoutePoint, window float64) {
+	half := window / 2
+	original := make([]float64, len(points))
+	for i, p := range points {
+		original[i] = p.Elevation
+	}
+
+	type fit struct{ elevation, grade float64 }
+	fits := make([]fit, len(points))
+
+	lo, hi := 0, 0
+	for i := range points {
+		for hi < len(points) && points[hi].Distance <= points[i].Distance+half {
+			hi++
+		}
+		for points[lo].Distance < points[i].Distance-half {
+			lo++
+		}
+
+		// Normal equations for y = c0 + c1·x + c2·x², x relative to the point
+		var s [5]float64 // Σx^k
+		var t [3]float64 // Σy·x^k
+		for j := lo; j < hi; j++ {
+			x := points[j].Distance - points[i].Distance
+			xk := 1.0
+			for k := 0; k < 5; k++ {
+				s[k] += xk
+				if k < 3 {
+					t[k] += original[j] * xk
+				}
+				xk *= x
+			}
+		}
+		c, ok := solve3([3][3]float64{{s[0], s[1], s[2]}, {s[1], s[2], s[3]}, {s[2], s[3], s[4]}}, t)
+		if !ok {
+			// Too few distinct points in the window: keep the raw value
+			fits[i] = fit{elevation: original[i], grade: math.NaN()}
+			continue
+		}
+		fits[i] = fit{elevation: c[0], grade: clampGrade(c[1] * 100)}
+	}
+
+	for i := range points {
+		points[i].Elevation = fits[i].elevation
+	}
+	for i := range points {
+		if math.IsNaN(fits[i].grade) {
+			a, b := points[max(i-1, 0)], points[min(i+1, len(points)-1)]
+			fits[i].grade = 0
+			if dist := b.Distance - a.Distance; dist > 0 {
+				fits[i].grade = clampGrade((b.Elevation - a.Elevation) / dist * 100)
+			}
+		}
+		points[i].Grade = fits[i].grade
+	}
+}
+
+// solve3 solves a 3x3 linear system by Cramer's rule.
+func solve3(m [3][3]float64, v [3]float64) ([3]float64, bool) {
+	det := func(a [3][3]float64) float64 {
+		return a[0][0]*(a[1][1]*a[2][2]-a[1][2]*a[2][1]) -
+			a[0][1]*(a[1][0]*a[2][2]-a[1][2]*a[2][0]) +
+			a[0][2]*(a[1][0]*a[2][1]-a[1][1]*a[2][0])
+	}
+	d := det(m)
+	if math.Abs(d) < 1e-9 {
+		return [3]float64{}, false
+	}
+	var out [3]float64
+	for col := 0; col < 3; col++ {
+		mc := m
+		for row := 0; row < 3; row++ {
+			mc[row][col] = v[row]
+		}
+		out[col] = det(mc) / d
+	}
+	return out, true
+}
+
+// kalmanSmooth estimates elevation and slope with a constant-slope Kalman
+// filter along the distance, followed by a Rauch–Tung–Striebel backward pass
+// so the result has no lag.
+func kalmanSmooth(points []domain.RoutePoint) {
+	n := len(points)
+	if n < 2 {
+		return
+	}
+
+	type state struct {
+		x [2]float64    // elevation, slope
+		p [2][2]float64 // covariance
+	}
+	pred := make([]state, n)
+	filt := make([]state, n)
+
+	r := kalmanElevationSigma * kalmanElevationSigma
+	q := kalmanSlopeSigma * kalmanSlopeSigma
+
+	filt[0] = state{x: [2]float64{points[0].Elevation, 0}, p: [2][2]float64{{r, 0}, {0, 0.01}}}
+	pred[0] = filt[0]
+
+	for i := 1; i < n; i++ {
+		ds := points[i].Distance - points[i-1].Distance
+		prev := filt[i-1]
+
+		// Predict: x' = F x, P' = F P Fᵀ + Q with F = [[1, ds], [0, 1]]
+		var s state
+		s.x = [2]float64{prev.x[0] + ds*prev.x[1], prev.x[1]}
+		s.p[0][0] = prev.p[0][0] + 2*ds*prev.p[0][1] + ds*ds*prev.p[1][1] + q*ds*ds*ds/3
+		s.p[0][1] = prev.p[0][1] + ds*prev.p[1][1] + q*ds*ds/2
+		s.p[1][0] = s.p[0][1]
+		s.p[1][1] = prev.p[1][1] + q*ds
+		pred[i] = s
+
+		// Update with the measured elevation (H = [1, 0])
+		k0 := s.p[0][0] / (s.p[0][0] + r)
+		k1 := s.p[1][0] / (s.p[0][0] + r)
+		innov := points[i].Elevation - s.x[0]
+		var f state
+		f.x = [2]float64{s.x[0] + k0*innov, s.x[1] + k1*innov}
+		f.p[0][0] = (1 - k0) * s.p[0][0]
+		f.p[0][1] = (1 - k0) * s.p[0][1]
+		f.p[1][0] = s.p[1][0] - k1*s.p[0][0]
+		f.p[1][1] = s.p[1][1] - k1*s.p[0][1]
+		filt[i] = f
+	}
+
+	// RTS smoother
+	smooth := filt[n-1].x
+	points[n-1].Elevation = smooth[0]
+	points[n-1].Grade = clampGrade(smooth[1] * 100)
+	for i := n - 2; i >= 0; i-- {
+		ds := points[i+1].Distance - points[i].Distance
+		f, p := filt[i], pred[i+1]
+
+		// C = P_f Fᵀ P_p⁻¹
+		pf := [2][2]float64{
+			{f.p[0][0] + ds*f.p[0][1], f.p[0][1]},
+			{f.p[1][0] + ds*f.p[1][1], f.p[1][1]},
+		}
+		det := p.p[0][0]*p.p[1][1] - p.p[0][1]*p.p[1][0]
+		if math.Abs(det) < 1e-12 {
+			smooth = f.x
+		} else {
+			inv := [2][2]float64{{p.p[1][1] / det, -p.p[0][1] / det}, {-p.p[1][0] / det, p.p[0][0] / det}}
+			c := [2][2]float64{
+				{pf[0][0]*inv[0][0] + pf[0][1]*inv[1][0], pf[0][0]*inv[0][1] + pf[0][1]*inv[1][1]},
+				{pf[1][0]*inv[0][0] + pf[1][1]*inv[1][0], pf[1][0]*inv[0][1] + pf[1][1]*inv[1][1]},
+			}
+			d0, d1 := smooth[0]-p.x[0], smooth[1]-p.x[1]
+			smooth = [2]float64{f.x[0] + c[0][0]*d0 + c[0][1]*d1, f.x[1] + c[1][0]*d0 + c[1][1]*d1}
+		}
+		points[i].Elevation = smooth[0]
+		points[i].Grade = clampGrade(smooth[1] * 100)
+	}
+}
+
+func clampGrade(grade float64) float64 {
+	return math.Max(-gradeLimit, math.Min(gradeLimit, grade))
+}
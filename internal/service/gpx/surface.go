@@ -0,0 +1,135 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"argus-cyclist/internal/domain"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// SurfaceOverride forces a surface on a distance range of the route.
+type SurfaceOverride struct {
+	From    float64 `json:"from"` // m
+	To      float64 `json:"to"`   // m
+	Surface string  `json:"surface"`
+}
+
+// surfaceAliases maps OSM "surface" tag values and common spellings to our surfaces.
+var surfaceAliases = map[string]string{
+	"asphalt":            domain.SurfaceAsphalt,
+	"paved":              domain.SurfaceAsphalt,
+	"tarmac":             domain.SurfaceAsphalt,
+	"chipseal":           domain.SurfaceAsphalt,
+	"concrete":           domain.SurfaceConcrete,
+	"concrete:plates":    domain.SurfaceConcrete,
+	"concrete:lanes":     domain.SurfaceConcrete,
+	"gravel":             domain.SurfaceGravel,
+	"fine_gravel":        domain.SurfaceGravel,
+	"compacted":          domain.SurfaceGravel,
+	"pebblestone":        domain.SurfaceGravel,
+	"dirt":               domain.SurfaceDirt,
+	"unpaved":            domain.SurfaceDirt,
+	"ground":             domain.SurfaceDirt,
+	"earth":              domain.SurfaceDirt,
+	"mud":                domain.SurfaceDirt,
+	"sand":               domain.SurfaceDirt,
+	"cobbles":            domain.SurfaceCobbles,
+	"cobblestone":        domain.SurfaceCobbles,
+	"sett":               domain.SurfaceCobbles,
+	"unhewn_cobblestone": domain.SurfaceCobbles,
+	"paving_stones":      domain.SurfaceCobbles,
+	"grass":              domain.SurfaceGrass,
+}
+
+// NormalizeSurface maps a free-form surface name to a domain surface ("" if unknown).
+func NormalizeSurface(name string) string {
+	return surfaceAliases[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// surfaceFromExtensions looks for a <surface> element at any depth of the extensions.
+func surfaceFromExtensions(ext gpx.Extension) string {
+	var search func(nodes []gpx.ExtensionNode) string
+	search = func(nodes []gpx.ExtensionNode) string {
+		for _, n := range nodes {
+			if strings.EqualFold(n.LocalName(), "surface") {
+				if s := NormalizeSurface(n.Data); s != "" {
+					return s
+				}
+			}
+			if s := search(n.Nodes); s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+	return search(ext.Nodes)
+}
+
+// surfaceSidecarPath is the overrides file loaded automatically next to a route
+// (e.g. "stage.gpx" -> "stage.surfaces.json").
+func surfaceSidecarPath(routePath string) string {
+	return strings.TrimSuffix(routePath, filepath.Ext(routePath)) + ".surfaces.json"
+}
+
+// LoadSurfaceOverrides reads a JSON list of SurfaceOverride and applies it to the loaded route.
+func (s *Service) LoadSurfaceOverrides(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var overrides []SurfaceOverride
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return fmt.Errorf("invalid surface overrides file: %w", err)
+	}
+	return s.ApplySurfaceOverrides(overrides)
+}
+
+// ApplySurfaceOverrides sets the surface of every point inside each range.
+// Later ranges win where they overlap.
+func (s *Service) ApplySurfaceOverrides(overrides []SurfaceOverride) error {
+	for _, o := range overrides {
+		surface := NormalizeSurface(o.Surface)
+		if surface == "" {
+			return fmt.Errorf("unknown surface: %s", o.Surface)
+		}
+		if o.To <= o.From {
+			return fmt.Errorf("invalid surface range: %.0f-%.0f m", o.From, o.To)
+		}
+	}
+	applySurfaceOverrides(s.points, overrides)
+	// Kept so they survive re-processing (resampling moves the points)
+	s.surfaceOverrides = append(s.surfaceOverrides, overrides...)
+	return nil
+}
+
+func applySurfaceOverrides(points []domain.RoutePoint, overrides []SurfaceOverride) {
+	for _, o := range overrides {
+		surface := NormalizeSurface(o.Surface)
+		for i := range points {
+			if points[i].Distance >= o.From && points[i].Distance < o.To {
+				points[i].Surface = surface
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"fmt"
+	"math"
+
+	"argus-cyclist/internal/domain"
+)
+
+const (
+	defaultSynthStep = 10.0                    // m between generated points
+	metersPerDegree  = 1000.0 * 10000.8 / 90.0 // Same as gpxgo, so reloaded routes keep their length
+)
+
+// RouteLeg is one part of a synthetic route: either a constant grade, or a
+// steady climb/descent to a target elevation.
+type RouteLeg struct {
+	Distance        float64  `json:"distance"`                   // m along the road
+	Grade           float64  `json:"grade"`                      // % (ignored when TargetElevation is set)
+	TargetElevation *float64 `json:"target_elevation,omitempty"` // m at the end of the leg
+	Surface         string   `json:"surface,omitempty"`
+}
+
+// SynthOptions place a synthetic route on the map.
+type SynthOptions struct {
+	StartLatitude  float64 `json:"start_latitude"`
+	StartLongitude float64 `json:"start_longitude"`
+	StartElevation float64 `json:"start_elevation"`
+	Heading        float64 `json:"heading"` // Initial direction (degrees, 0 = north)
+	Step           float64 `json:"step"`    // m between points (0 = 10 m)
+}
+
+// KOMOrigin is where synthetic KOM, lab and benchmark routes start (the
+// built-in KOM segment's start).
+var KOMOrigin = SynthOptions{StartLatitude: -23.56, StartLongitude: -46.65, StartElevation: 760, Heading: 225}
+
+// SynthesizeRoute builds a route from legs. The road winds gently, and more
+// tightly on steep legs, like a mountain road with hairpins.
+func SynthesizeRoute(legs []RouteLeg, opts SynthOptions) ([]domain.RoutePoint, error) {
+	if len(legs) == 0 {
+		return nil, fmt.Errorf("the route needs at least one leg")
+	}
+	step := opts.Step
+	if step <= 0 {
+		step = defaultSynthStep
+	}
+
+	ele := opts.StartElevation
+	grades := make([]float64, len(legs))
+	for i, leg := range legs {
+		if leg.Distance <= 0 {
+			return nil, fmt.Errorf("leg %d: distance must be positive", i+1)
+		}
+		grade := leg.Grade
+		if leg.TargetElevation != nil {
+			// The grade is measured on the horizontal, the leg length along the road
+			rise := *leg.TargetElevation - ele
+			horizontal := math.Sqrt(math.Max(leg.Distance*leg.Distance-rise*rise, 0))
+			if horizontal == 0 {
+				return nil, fmt.Errorf("leg %d: %.0f m cannot climb %.0f m", i+1, leg.Distance, rise)
+			}
+			grade = rise / horizontal * 100
+		}
+		if math.Abs(grade) > gradeLimit {
+			return nil, fmt.Errorf("leg %d: grade %.1f%% is steeper than %.0f%%", i+1, grade, gradeLimit)
+		}
+		if leg.Surface != "" && NormalizeSurface(leg.Surface) == "" {
+			return nil, fmt.Errorf("leg %d: unknown surface: %s", i+1, leg.Surface)
+		}
+		grades[i] = grade
+		ele += leg.Distance * math.Sin(math.Atan(grade/100))
+	}
+
+	lat, lon := opts.StartLatitude, opts.StartLongitude
+	ele = opts.StartElevation
+	dist := 0.0
+	points := []domain.RoutePoint{{Latitude: lat, Longitude: lon, Elevation: ele, Grade: grades[0], Surface: NormalizeSurface(legs[0].Surface)}}
+
+	for i, leg := range legs {
+		slope := math.Atan(grades[i] / 100)
+		surface := NormalizeSurface(leg.Surface)
+		// Bends get sharper with the grade: ±30° on the flat, switchbacks above ~8%
+		amplitude := math.Min(30+8*math.Abs(grades[i]), 100)
+
+		for done := 0.0; done < leg.Distance-1e-6; {
+			d := math.Min(step, leg.Distance-done)
+			done += d
+			dist += d
+
+			heading := opts.Heading + amplitude*math.Sin(2*math.Pi*dist/1500) + 10*math.Sin(2*math.Pi*dist/400)
+			horizontal := d * math.Cos(slope)
+			h := heading * math.Pi / 180
+			lat += horizontal * math.Cos(h) / metersPerDegree
+			lon += horizontal * math.Sin(h) / (metersPerDegree * math.Cos(lat*math.Pi/180))
+			ele += d * math.Sin(slope)
+
+			// The grade of a point is the grade of the road after it
+			grade := grades[i]
+			if done >= leg.Distance-1e-6 && i+1 < len(legs) {
+				grade = grades[i+1]
+			}
+			points = append(points, domain.RoutePoint{
+				Latitude:  lat,
+				Longitude: lon,
+				Elevation: ele,
+				Distance:  dist,
+				Grade:     grade,
+				Surface:   surface,
+			})
+		}
+	}
+	return points, nil
+}
+
+// GradeScheduleLegs turns evenly spaced grades over a distance into legs, ramping
+// linearly between consecutive grades (e.g. a KOM climb drawn as a grade list).
+func GradeScheduleLegs(grades []float64, length, step float64) []RouteLeg {
+	if len(grades) == 0 || length <= 0 {
+		return nil
+	}
+	if len(grades) == 1 {
+		return []RouteLeg{{Distance: length, Grade: grades[0]}}
+	}
+	if step <= 0 {
+		step = defaultSynthStep
+	}
+
+	var legs []RouteLeg
+	span := length / float64(len(grades)-1)
+	for d := 0.0; d < length-1e-6; d += step {
+		pos := d / span
+		idx := min(int(pos), len(grades)-2)
+		t := pos - float64(idx)
+		legs = append(legs, RouteLeg{
+			Distance: math.Min(step, length-d),
+			Grade:    grades[idx] + t*(grades[idx+1]-grades[idx]),
+		})
+	}
+	return legs
+}
@@ -0,0 +1,158 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package gpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxVideoRate is the fastest playback browsers support (HTMLMediaElement.playbackRate).
+const maxVideoRate = 16.0
+
+// VideoSyncPoint ties a video timestamp to a route position.
+type VideoSyncPoint struct {
+	Time     float64 `json:"time"`     // s into the video
+	Distance float64 `json:"distance"` // Route position (m)
+}
+
+// RouteVideo is a ride-along video of the route and its sync map.
+type RouteVideo struct {
+	File string           `json:"file"` // Video path (relative to the route file when saved next to it)
+	Sync []VideoSyncPoint `json:"sync"` // Increasing in both time and distance
+}
+
+// Validate checks the sync map.
+func (v RouteVideo) Validate() error {
+	if v.File == "" {
+		return fmt.Errorf("no video file")
+	}
+	if len(v.Sync) < 2 {
+		return fmt.Errorf("the sync map needs at least two points")
+	}
+	for i := 1; i < len(v.Sync); i++ {
+		a, b := v.Sync[i-1], v.Sync[i]
+		if b.Time <= a.Time || b.Distance <= a.Distance {
+			return fmt.Errorf("sync point %d: time and distance must increase", i+1)
+		}
+	}
+	return nil
+}
+
+// Playback returns the video time for a route position and the playback rate
+// that matches the rider's speed (m/s) to the speed the video was filmed at.
+// Outside the synced range the video holds its first or last frame.
+func (v RouteVideo) Playback(distance, speed float64) (float64, float64) {
+	sync := v.Sync
+	if len(sync) < 2 {
+		return 0, 0
+	}
+	if distance < sync[0].Distance {
+		return sync[0].Time, 0
+	}
+	last := sync[len(sync)-1]
+	if distance >= last.Distance {
+		return last.Time, 0
+	}
+	i := 1
+	for sync[i].Distance < distance {
+		i++
+	}
+	a, b := sync[i-1], sync[i]
+	ratio := (distance - a.Distance) / (b.Distance - a.Distance)
+	filmed := (b.Distance - a.Distance) / (b.Time - a.Time)
+	return a.Time + ratio*(b.Time-a.Time), math.Min(math.Max(speed, 0)/filmed, maxVideoRate)
+}
+
+// videoSidecarPath is the video file of a route ("stage.gpx" -> "stage.video.json").
+func videoSidecarPath(routePath string) string {
+	return strings.TrimSuffix(routePath, filepath.Ext(routePath)) + ".video.json"
+}
+
+// GetVideo returns the video of the loaded route (nil = none).
+func (s *Service) GetVideo() *RouteVideo {
+	return s.video
+}
+
+// ReadVideoFile reads a video sync file ({file, sync}). A relative video
+// path is resolved against the sync file's folder.
+func ReadVideoFile(path string) (*RouteVideo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var video RouteVideo
+	if err := json.Unmarshal(data, &video); err != nil {
+		return nil, fmt.Errorf("invalid video sync file: %w", err)
+	}
+	if video.File != "" && !filepath.IsAbs(video.File) {
+		video.File = filepath.Join(filepath.Dir(path), video.File)
+	}
+	return &video, nil
+}
+
+// checkVideo validates a video against the loaded route.
+func (s *Service) checkVideo(video *RouteVideo) error {
+	if len(s.points) < 2 {
+		return fmt.Errorf("no route loaded")
+	}
+	if err := video.Validate(); err != nil {
+		return err
+	}
+	if last := video.Sync[len(video.Sync)-1]; last.Distance > s.GetTotalDistance()+1 {
+		return fmt.Errorf("the sync map goes past the route end (%.0f m)", s.GetTotalDistance())
+	}
+	if _, err := os.Stat(video.File); err != nil {
+		return fmt.Errorf("video file: %w", err)
+	}
+	return nil
+}
+
+// SetVideo attaches a video to the loaded route (nil removes it). When the
+// route came from a file it is saved next to it and reused on load.
+func (s *Service) SetVideo(video *RouteVideo) error {
+	if video != nil {
+		if err := s.checkVideo(video); err != nil {
+			return err
+		}
+	}
+	s.video = video
+
+	if s.sourcePath == "" {
+		return nil
+	}
+	sidecar := videoSidecarPath(s.sourcePath)
+	if video == nil {
+		if err := os.Remove(sidecar); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	saved := *video
+	if rel, err := filepath.Rel(filepath.Dir(sidecar), video.File); err == nil && !strings.HasPrefix(rel, "..") {
+		saved.File = rel
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sidecar, data, 0644)
+}
@@ -0,0 +1,188 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Drafting model
+const (
+	MaxDraftGap       = 10.0 // Beyond this gap (m) there is no slipstream
+	singleWheelDraft  = 0.27 // Drag reduction right behind one rider
+	groupDraftPerRide = 0.04 // Extra reduction for every additional rider ahead
+	maxGroupDraft     = 0.40 // Sitting deep in a bunch
+	groupChainGap     = 3.0  // Riders closer than this (m) form one group
+)
+
+// DraftReduction returns the fraction (0-1) of aerodynamic drag saved when
+// following groupSize riders at the given gap (m). It fades linearly to zero
+// at MaxDraftGap.
+func DraftReduction(gap float64, groupSize int) float64 {
+	if groupSize <= 0 || gap < 0 || gap >= MaxDraftGap {
+		return 0
+	}
+	reduction := singleWheelDraft + groupDraftPerRide*float64(groupSize-1)
+	if reduction > maxGroupDraft {
+		reduction = maxGroupDraft
+	}
+	return reduction * (1 - gap/MaxDraftGap)
+}
+
+// RouteLookup returns the grade (%) and altitude (m) at a route distance.
+type RouteLookup func(distance float64) (grade float64, altitude float64)
+
+// VirtualRider is a computer-controlled companion riding at a fixed power.
+type VirtualRider struct {
+	ID       int
+	Name     string
+	Power    float64 // W
+	Distance float64 // Accumulated distance (m)
+	Pacing   string  // Pacing mode for pacer bots ("" for plain draft companions)
+	engine   *Engine
+}
+
+// VirtualRiderState is the frontend view of a virtual rider.
+type VirtualRiderState struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Power    float64 `json:"power"`
+	Distance float64 `json:"distance"`
+	Speed    float64 `json:"speed"` // km/h
+	Gap      float64 `json:"gap"`   // Metres ahead (+) or behind (-) the user
+	Pacing   string  `json:"pacing,omitempty"`
+}
+
+// Group holds the virtual riders sharing the route with the user.
+// It is stepped by the game loop and edited by frontend bindings, hence the mutex.
+type Group struct {
+	mu     sync.Mutex
+	riders []*VirtualRider
+	nextID int
+}
+
+func NewGroup() *Group {
+	return &Group{nextID: 1}
+}
+
+// Spawn adds count riders at the given power, the first one at startDistance
+// and the rest spaced behind it in a tight line.
+func (g *Group) Spawn(count int, watts float64, startDistance float64, riderWeight, bikeWeight float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i := 0; i < count; i++ {
+		e := NewEngine(riderWeight, bikeWeight)
+		g.riders = append(g.riders, &VirtualRider{
+			ID:       g.nextID,
+			Name:     fmt.Sprintf("Rider %d", g.nextID),
+			Power:    watts,
+			Distance: startDistance - float64(i)*2.0,
+			engine:   e,
+		})
+		g.nextID++
+	}
+}
+
+// Clear removes every virtual rider.
+func (g *Group) Clear() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.riders = nil
+}
+
+// Restart brings every virtual rider to a standstill for a new session,
+// keeping its gap to the user, who was at userDistance and restarts at 0.
+func (g *Group) Restart(userDistance float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, r := range g.riders {
+		r.Distance -= userDistance
+		r.engine.Reset()
+	}
+}
+
+// Len returns the number of virtual riders.
+func (g *Group) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.riders)
+}
+
+// Step advances every virtual rider by dt seconds on the route.
+func (g *Group) Step(route RouteLookup, dt float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, r := range g.riders {
+		grade, alt := route(r.Distance)
+		r.Distance += r.engine.Step(r.Power, grade, alt, dt) * dt
+	}
+}
+
+// DraftFor returns the drag reduction for a rider at the given distance,
+// based on the gap to the nearest virtual rider ahead and the size of the
+// group that rider belongs to.
+func (g *Group) DraftFor(distance float64) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var ahead []float64
+	for _, r := range g.riders {
+		if r.Distance > distance {
+			ahead = append(ahead, r.Distance)
+		}
+	}
+	if len(ahead) == 0 {
+		return 0
+	}
+	sort.Float64s(ahead)
+
+	gap := ahead[0] - distance
+	groupSize := 1
+	for i := 1; i < len(ahead); i++ {
+		if ahead[i]-ahead[i-1] > groupChainGap {
+			break
+		}
+		groupSize++
+	}
+
+	return DraftReduction(gap, groupSize)
+}
+
+// States returns a snapshot of the virtual riders relative to the user.
+func (g *Group) States(userDistance float64) []VirtualRiderState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	states := []VirtualRiderState{}
+	for _, r := range g.riders {
+		states = append(states, VirtualRiderState{
+			ID:       r.ID,
+			Name:     r.Name,
+			Power:    r.Power,
+			Distance: r.Distance,
+			Speed:    r.engine.Velocity() * 3.6,
+			Gap:      r.Distance - userDistance,
+			Pacing:   r.Pacing,
+		})
+	}
+	return states
+}
@@ -20,16 +20,66 @@ import "math"
 
 // Physical constants for cycling
 const (
-	Gravity    = 9.81
-	Rho        = 1.225 // Air density (sea level)
-	CdA        = 0.32  // Drag coefficient
-	Crr        = 0.005 // Rolling resistance coefficient
-	Drivetrain = 0.96  // Drivetrain efficiency
+	Gravity = 9.81
+
+	// Defaults used when the profile does not override them (road bike, hoods).
+	DefaultCdA        = 0.32  // Drag area (m²)
+	DefaultCrr        = 0.005 // Rolling resistance coefficient
+	DefaultDrivetrain = 0.96  // Drivetrain efficiency
+
+	// Standard atmosphere at sea level: 15 °C and 1013.25 hPa give Rho = 1.225 kg/m³.
+	DefaultTemperatureC = 15.0
+	DefaultPressureHPa  = 1013.25
+
+	gasConstantDryAir = 287.05 // J/(kg·K)
 )
 
+// Params holds the bike/position-dependent coefficients of the physics model.
+// Zero values fall back to the defaults above. The temperature is a pointer
+// because 0 °C and below are valid: nil means "not set".
+type Params struct {
+	CdA          float64  `json:"cda"`
+	Crr          float64  `json:"crr"`
+	Drivetrain   float64  `json:"drivetrain"`
+	TemperatureC *float64 `json:"temperature_c,omitempty"`
+	PressureHPa  float64  `json:"pressure_hpa"` // Sea-level pressure
+}
+
+// BikePresets are typical values for common bikes and riding positions.
+var BikePresets = map[string]Params{
+	"road_hoods": {CdA: 0.32, Crr: 0.005, Drivetrain: 0.96},
+	"road_drops": {CdA: 0.29, Crr: 0.005, Drivetrain: 0.96},
+	"tt":         {CdA: 0.23, Crr: 0.004, Drivetrain: 0.975},
+	"gravel":     {CdA: 0.35, Crr: 0.007, Drivetrain: 0.96},
+	"mtb":        {CdA: 0.42, Crr: 0.012, Drivetrain: 0.95},
+}
+
+// PresetParams returns the preset for a bike type, or the road hoods defaults if unknown.
+func PresetParams(bikeType string) Params {
+	if p, ok := BikePresets[bikeType]; ok {
+		return p
+	}
+	return BikePresets["road_hoods"]
+}
+
 type Engine struct {
-	UserWeight float64 // kg
-	BikeWeight float64 // kg
+	UserWeight   float64 // kg
+	BikeWeight   float64 // kg
+	CdA          float64 // m²
+	Crr          float64
+	Drivetrain   float64
+	TemperatureC float64 // Air temperature (°C)
+	PressureHPa  float64 // Sea-level air pressure (hPa)
+
+	SpeedModel   string  // SpeedModelMomentum or SpeedModelSteady
+	WheelInertia float64 // Rotational inertia of both wheels (kg·m²)
+	WheelRadius  float64 // m
+
+	DraftReduction float64 // Fraction of drag saved in a slipstream (0-1), set every tick
+	Headwind       float64 // Wind component against the rider (m/s), set every tick
+	Surface        string  // Road surface under the rider, set every tick
+
+	velocity float64 // Current speed for the momentum model (m/s)
 }
 
 func NewEngine(userWeight, bikeWeight float64) *Engine {
@@ -39,16 +89,61 @@ func NewEngine(userWeight, bikeWeight float64) *Engine {
 	if bikeWeight == 0 {
 		bikeWeight = 9.0
 	}
-	return &Engine{
-		UserWeight: userWeight,
-		BikeWeight: bikeWeight,
+	e := &Engine{
+		UserWeight:   userWeight,
+		BikeWeight:   bikeWeight,
+		SpeedModel:   SpeedModelMomentum,
+		WheelInertia: DefaultWheelInertia,
+		WheelRadius:  DefaultWheelRadius,
+	}
+	e.ApplyParams(Params{})
+	return e
+}
+
+// ApplyParams sets the physics coefficients, using defaults for unset values.
+func (e *Engine) ApplyParams(p Params) {
+	e.CdA = orDefault(p.CdA, DefaultCdA)
+	e.Crr = orDefault(p.Crr, DefaultCrr)
+	e.Drivetrain = orDefault(p.Drivetrain, DefaultDrivetrain)
+	e.TemperatureC = DefaultTemperatureC
+	if p.TemperatureC != nil {
+		e.TemperatureC = *p.TemperatureC
+	}
+	e.PressureHPa = orDefault(p.PressureHPa, DefaultPressureHPa)
+}
+
+// EffectiveCdA is the drag area after the drafting reduction.
+func (e *Engine) EffectiveCdA() float64 {
+	return e.CdA * (1 - e.DraftReduction)
+}
+
+// RollingCrr is the rolling resistance coefficient on the current surface.
+func (e *Engine) RollingCrr() float64 {
+	return e.Crr * SurfaceCrrFactor(e.Surface)
+}
+
+func orDefault(v, def float64) float64 {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
+// AirDensity returns Rho (kg/m³) at the given altitude using the barometric
+// formula for pressure and the ideal gas law with the configured temperature.
+func (e *Engine) AirDensity(altitude float64) float64 {
+	pressure := e.PressureHPa * 100.0 * math.Pow(1-2.25577e-5*altitude, 5.25588)
+	tempK := e.TemperatureC + 273.15
+	if pressure <= 0 || tempK <= 0 {
+		return 0
 	}
+	return pressure / (gasConstantDryAir * tempK)
 }
 
-// CalculateSpeed estimates speed (m/s) based on power and grade.
-func (e *Engine) CalculateSpeed(watts float64, gradePercent float64) float64 {
+// CalculateSpeed estimates speed (m/s) based on power, grade and altitude (m).
+func (e *Engine) CalculateSpeed(watts float64, gradePercent float64, altitude float64) float64 {
 	totalMass := e.UserWeight + e.BikeWeight
-	powerWheel := watts * Drivetrain
+	powerWheel := watts * e.Drivetrain
 
 	// Grade: % -> Radians
 	theta := math.Atan(gradePercent / 100.0)
@@ -58,10 +153,10 @@ func (e *Engine) CalculateSpeed(watts float64, gradePercent float64) float64 {
     // Linear Forces (Gravity + Rolling)
     // Gravity assists (-) or hinders (+)
 	forceGravity := totalMass * Gravity * sinTheta
-	forceRolling := totalMass * Gravity * cosTheta * Crr
+	forceRolling := totalMass * Gravity * cosTheta * e.RollingCrr()
 	
 	forceLinear := forceGravity + forceRolling
-	constAero := 0.5 * Rho * CdA
+	constAero := 0.5 * e.AirDensity(altitude) * e.EffectiveCdA()
 
     // Robust Iterative Solution (Binary Search / Bisection)
     // For steep descents, speed can be high even with 0 watts.
@@ -75,7 +170,9 @@ func (e *Engine) CalculateSpeed(watts float64, gradePercent float64) float64 {
 	for i := 0; i < 20; i++ {
 		mid := (low + high) / 2
 		
-		powerRequired := (constAero * math.Pow(mid, 3)) + (forceLinear * mid)
+		// Drag depends on the apparent air speed (rider speed + headwind)
+		airSpeed := mid + e.Headwind
+		powerRequired := (constAero * airSpeed * math.Abs(airSpeed) * mid) + (forceLinear * mid)
 		
 		if powerRequired < powerWheel {
 			low = mid
@@ -0,0 +1,261 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	// GhostSplitLength is the distance (m) of each section compared in the summary.
+	GhostSplitLength = 1000.0
+	// Record gaps longer than this (s) are treated as a pause in the recording.
+	ghostPauseGap = 5.0
+)
+
+// GhostState is the live comparison between the rider and the ghost.
+type GhostState struct {
+	ActivityID uint    `json:"activity_id"`
+	Name       string  `json:"name"`
+	Distance   float64 `json:"distance"`    // Ghost distance since the start (m)
+	Latitude   float64 `json:"lat"`         // Ghost position on the route
+	Longitude  float64 `json:"lon"`         //
+	GapSeconds float64 `json:"gap_seconds"` // + = rider behind the ghost
+	GapMeters  float64 `json:"gap_meters"`  // + = ghost ahead of the rider
+	Finished   bool    `json:"finished"`    // Rider went past the end of the ghost's ride
+}
+
+// GhostSplit compares one section of the route.
+type GhostSplit struct {
+	FromDistance float64 `json:"from_distance"`
+	ToDistance   float64 `json:"to_distance"`
+	RiderTime    float64 `json:"rider_time"` // s
+	GhostTime    float64 `json:"ghost_time"` // s
+	Delta        float64 `json:"delta"`      // RiderTime - GhostTime (- = time gained)
+	Partial      bool    `json:"partial"`    // Section still being ridden (up to the rider's position)
+}
+
+// GhostSummary tells where time was gained or lost against the ghost.
+type GhostSummary struct {
+	ActivityID uint         `json:"activity_id"`
+	Name       string       `json:"name"`
+	Splits     []GhostSplit `json:"splits"`
+	FinalGap   float64      `json:"final_gap"`   // s, + = finished behind
+	TimeGained float64      `json:"time_gained"` // Sum of the splits where the rider was faster (s)
+	TimeLost   float64      `json:"time_lost"`   // Sum of the splits where the rider was slower (s)
+	BestSplit  int          `json:"best_split"`  // Index of the biggest gain (-1 if none)
+	WorstSplit int          `json:"worst_split"` // Index of the biggest loss (-1 if none)
+}
+
+// Ghost replays a recorded distance/time stream alongside the rider.
+// It is updated by the game loop and read by frontend bindings, hence the mutex.
+type Ghost struct {
+	mu         sync.Mutex
+	activityID uint
+	name       string
+	times      []float64 // Elapsed ride time (s), pauses removed
+	distances  []float64 // Non-decreasing distance (m)
+
+	baseTime, baseDist float64 // Rider time/distance when the race started
+	lastTime, lastDist float64 // Rider time/distance at the previous update (relative)
+	nextSplit          float64
+	closed             float64 // End of the last closed split (m)
+	splits             []GhostSplit
+	last               GhostState
+}
+
+// NewGhost builds a ghost from a recorded stream of elapsed seconds and distances.
+func NewGhost(activityID uint, name string, elapsed, distance []float64) (*Ghost, error) {
+	n := len(elapsed)
+	if len(distance) < n {
+		n = len(distance)
+	}
+	if n < 2 {
+		return nil, fmt.Errorf("activity has no distance stream")
+	}
+
+	g := &Ghost{activityID: activityID, name: name}
+	t, d := 0.0, 0.0
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			dt := elapsed[i] - elapsed[i-1]
+			if dt > ghostPauseGap {
+				dt = 1
+			}
+			if dt > 0 {
+				t += dt
+			}
+		}
+		if distance[i] > d {
+			d = distance[i]
+		}
+		g.times = append(g.times, t)
+		g.distances = append(g.distances, d)
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("activity has no distance stream")
+	}
+	g.Start(0, 0)
+	return g, nil
+}
+
+// Start (re)starts the race from the rider's current session time and distance.
+func (g *Ghost) Start(riderTime, riderDist float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.baseTime, g.baseDist = riderTime, riderDist
+	g.lastTime, g.lastDist = 0, 0
+	g.nextSplit = GhostSplitLength
+	g.closed = 0
+	g.splits = nil
+	g.last = GhostState{ActivityID: g.activityID, Name: g.name}
+}
+
+// TotalDistance is the length of the ghost's ride (m).
+func (g *Ghost) TotalDistance() float64 {
+	return g.distances[len(g.distances)-1]
+}
+
+// distanceAt interpolates the ghost distance after t seconds.
+func (g *Ghost) distanceAt(t float64) float64 {
+	i := sort.SearchFloat64s(g.times, t)
+	if i >= len(g.times) {
+		return g.distances[len(g.distances)-1]
+	}
+	if i == 0 {
+		return g.distances[0]
+	}
+	return interpolate(g.times[i-1], g.times[i], g.distances[i-1], g.distances[i], t)
+}
+
+// timeAt interpolates when the ghost reached distance d. ok is false beyond its ride.
+func (g *Ghost) timeAt(d float64) (float64, bool) {
+	i := sort.SearchFloat64s(g.distances, d)
+	if i >= len(g.distances) {
+		return 0, false
+	}
+	if i == 0 {
+		return g.times[0], true
+	}
+	return interpolate(g.distances[i-1], g.distances[i], g.times[i-1], g.times[i], d), true
+}
+
+func interpolate(x0, x1, y0, y1, x float64) float64 {
+	if x1 <= x0 {
+		return y1
+	}
+	return y0 + (x-x0)/(x1-x0)*(y1-y0)
+}
+
+// Update advances the race to the rider's session time and distance.
+func (g *Ghost) Update(riderTime, riderDist float64) GhostState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	t := riderTime - g.baseTime
+	d := riderDist - g.baseDist
+
+	// Close every split crossed since the last update; the last one ends
+	// short at the ghost's finish
+	for {
+		to := math.Min(g.nextSplit, g.TotalDistance())
+		if d < to || to <= g.closed {
+			break
+		}
+		crossTime := interpolate(g.lastDist, d, g.lastTime, t, to)
+		ghostTime, _ := g.timeAt(to)
+		g.splits = append(g.splits, g.split(to, crossTime, ghostTime))
+		g.closed = to
+		g.nextSplit += GhostSplitLength
+	}
+	g.lastTime, g.lastDist = t, d
+
+	ghostDist := g.distanceAt(t)
+	g.last.Distance = g.baseDist + ghostDist
+	g.last.GapMeters = ghostDist - d
+	if ghostTime, ok := g.timeAt(d); ok {
+		g.last.GapSeconds = t - ghostTime
+	} else {
+		// Past the end of the recording: keep the gap measured at the ghost's finish.
+		g.last.Finished = true
+	}
+	return g.last
+}
+
+// split compares the section from the last closed split to the given distance,
+// reached by the rider and the ghost at the given times.
+func (g *Ghost) split(to, riderTime, ghostTime float64) GhostSplit {
+	prevRider, prevGhost := g.splitTotals()
+	s := GhostSplit{
+		FromDistance: g.closed,
+		ToDistance:   to,
+		RiderTime:    riderTime - prevRider,
+		GhostTime:    ghostTime - prevGhost,
+	}
+	s.Delta = s.RiderTime - s.GhostTime
+	return s
+}
+
+// splitTotals sums the rider and ghost times of the closed splits.
+func (g *Ghost) splitTotals() (float64, float64) {
+	rider, ghost := 0.0, 0.0
+	for _, s := range g.splits {
+		rider += s.RiderTime
+		ghost += s.GhostTime
+	}
+	return rider, ghost
+}
+
+// Summary reports the splits closed so far, plus the section being ridden,
+// and where time was gained or lost.
+func (g *Ghost) Summary() GhostSummary {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	summary := GhostSummary{
+		ActivityID: g.activityID,
+		Name:       g.name,
+		Splits:     append([]GhostSplit(nil), g.splits...),
+		FinalGap:   g.last.GapSeconds,
+		BestSplit:  -1,
+		WorstSplit: -1,
+	}
+	if g.lastDist > g.closed && g.closed < g.TotalDistance() {
+		if ghostTime, ok := g.timeAt(g.lastDist); ok {
+			partial := g.split(g.lastDist, g.lastTime, ghostTime)
+			partial.Partial = true
+			summary.Splits = append(summary.Splits, partial)
+		}
+	}
+	for i, s := range summary.Splits {
+		if s.Delta < 0 {
+			summary.TimeGained -= s.Delta
+			if summary.BestSplit < 0 || s.Delta < summary.Splits[summary.BestSplit].Delta {
+				summary.BestSplit = i
+			}
+		} else if s.Delta > 0 {
+			summary.TimeLost += s.Delta
+			if summary.WorstSplit < 0 || s.Delta > summary.Splits[summary.WorstSplit].Delta {
+				summary.WorstSplit = i
+			}
+		}
+	}
+	return summary
+}
@@ -0,0 +1,114 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sim
+
+import "math"
+
+// Speed models
+const (
+	SpeedModelMomentum = "momentum" // Integrates the net force every tick
+	SpeedModelSteady   = "steady"   // Solves steady-state speed every tick (legacy)
+)
+
+const (
+	DefaultWheelInertia = 0.14  // Two 700c road wheels (kg·m²)
+	DefaultWheelRadius  = 0.335 // 700x25c (m)
+
+	maxSubStep  = 0.1  // Integration step (s)
+	maxStepTime = 5.0  // Longer gaps (sensor dropouts) are clamped
+	maxVelocity = 40.0 // Same ceiling as the steady-state solver (~144 km/h)
+
+	// Below this speed (m/s) the pedal force is taken as P / minDriveSpeed,
+	// roughly the force a rider can put down when starting off.
+	minDriveSpeed = 1.0
+)
+
+// EffectiveMass is the rider+bike mass plus the wheels' rotational inertia
+// expressed as equivalent linear mass (I / r²).
+func (e *Engine) EffectiveMass() float64 {
+	mass := e.UserWeight + e.BikeWeight
+	if e.WheelRadius > 0 {
+		mass += e.WheelInertia / (e.WheelRadius * e.WheelRadius)
+	}
+	return mass
+}
+
+// forces returns the forces (N) along the road at speed v: the gravity and
+// aerodynamic components (positive = against the rider) and the rolling
+// resistance magnitude, which only ever opposes motion.
+func (e *Engine) forces(v, gradePercent, altitude float64) (gravity, aero, rolling float64) {
+	totalMass := e.UserWeight + e.BikeWeight
+	theta := math.Atan(gradePercent / 100.0)
+
+	gravity = totalMass * Gravity * math.Sin(theta)
+	rolling = totalMass * Gravity * math.Cos(theta) * e.RollingCrr()
+	airSpeed := v + e.Headwind
+	aero = 0.5 * e.AirDensity(altitude) * e.EffectiveCdA() * airSpeed * math.Abs(airSpeed)
+	return gravity, aero, rolling
+}
+
+// Step advances the speed model by dt seconds and returns the new speed (m/s).
+// With the momentum model the net force accelerates the effective mass, so
+// speed carries over rollers, builds up gradually on descents and a rider
+// standing on a descent starts rolling without pedalling.
+func (e *Engine) Step(watts, gradePercent, altitude, dt float64) float64 {
+	if e.SpeedModel == SpeedModelSteady {
+		e.velocity = e.CalculateSpeed(watts, gradePercent, altitude)
+		return e.velocity
+	}
+	if dt <= 0 {
+		return e.velocity
+	}
+	if dt > maxStepTime {
+		dt = maxStepTime
+	}
+
+	mass := e.EffectiveMass()
+	powerWheel := math.Max(watts*e.Drivetrain, 0)
+
+	for remaining := dt; remaining > 0; remaining -= maxSubStep {
+		h := math.Min(remaining, maxSubStep)
+		v := e.velocity
+		gravity, aero, rolling := e.forces(v, gradePercent, altitude)
+
+		// Pedal force P/v, bounded near standstill where it would be infinite
+		net := powerWheel/math.Max(v, minDriveSpeed) - gravity - aero
+		if v > 0 {
+			net -= rolling
+		} else if net > rolling {
+			net -= rolling
+		} else {
+			// Rolling resistance holds the bike still
+			net = 0
+		}
+
+		// Stalled on a climb: the bike stops instead of rolling backwards.
+		e.velocity = math.Min(math.Max(v+net/mass*h, 0), maxVelocity)
+	}
+
+	return e.velocity
+}
+
+// Velocity returns the current speed of the momentum model (m/s).
+func (e *Engine) Velocity() float64 {
+	return e.velocity
+}
+
+// Reset brings the rider to a standstill (new session or pause).
+func (e *Engine) Reset() {
+	e.velocity = 0
+}
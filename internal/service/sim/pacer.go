@@ -0,0 +1,138 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// Pacing modes for pacer bots
+const (
+	PacingWatts      = "watts"       // Fixed power (W)
+	PacingWkg        = "wkg"         // Fixed power-to-weight ratio (W/kg)
+	PacingFTP        = "ftp"         // Fraction of the user's FTP (e.g. 0.85)
+	PacingTargetTime = "target_time" // Constant power that covers the route in the given seconds
+)
+
+// predictionStep is the distance (m) over which speed is assumed constant when predicting ride time.
+const predictionStep = 10.0
+
+// PacerConfig describes a pacer bot.
+type PacerConfig struct {
+	Name   string  `json:"name"`
+	Mode   string  `json:"mode"`
+	Value  float64 `json:"value"`  // W, W/kg, FTP fraction or seconds, depending on Mode
+	Weight float64 `json:"weight"` // Bot weight (kg), 0 = same as the user
+}
+
+// PredictTime estimates how long (s) riding length metres from startDistance
+// takes at constant power, using the engine's steady-state speed.
+func PredictTime(e *Engine, route RouteLookup, startDistance, length, watts float64) float64 {
+	total := 0.0
+	for d := 0.0; d < length; d += predictionStep {
+		ds := math.Min(predictionStep, length-d)
+		grade, alt := route(startDistance + d + ds/2)
+		v := e.CalculateSpeed(watts, grade, alt)
+		if v < 0.5 {
+			v = 0.5 // Walking pace: keeps the prediction finite on walls at low power
+		}
+		total += ds / v
+	}
+	return total
+}
+
+// PowerForTime finds the constant power that covers the distance in targetSeconds.
+func PowerForTime(e *Engine, route RouteLookup, startDistance, length, targetSeconds float64) (float64, error) {
+	if length <= 0 || targetSeconds <= 0 {
+		return 0, fmt.Errorf("invalid target: %.0f m in %.0f s", length, targetSeconds)
+	}
+
+	low, high := 0.0, 2000.0
+	if PredictTime(e, route, startDistance, length, high) > targetSeconds {
+		return 0, fmt.Errorf("target time is not achievable (over %.0f W)", high)
+	}
+	for i := 0; i < 30; i++ {
+		mid := (low + high) / 2
+		if PredictTime(e, route, startDistance, length, mid) > targetSeconds {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return high, nil
+}
+
+// PacerPower resolves a pacer's configuration to a constant power (W).
+// ftp is the user's FTP; route and length are only used by PacingTargetTime.
+func PacerPower(cfg PacerConfig, e *Engine, ftp float64, route RouteLookup, startDistance, length float64) (float64, error) {
+	if cfg.Value <= 0 {
+		return 0, fmt.Errorf("pacer value must be positive")
+	}
+	switch cfg.Mode {
+	case PacingWatts:
+		return cfg.Value, nil
+	case PacingWkg:
+		return cfg.Value * e.UserWeight, nil
+	case PacingFTP:
+		if ftp <= 0 {
+			return 0, fmt.Errorf("FTP is not set")
+		}
+		return cfg.Value * ftp, nil
+	case PacingTargetTime:
+		if route == nil {
+			return 0, fmt.Errorf("no route loaded")
+		}
+		return PowerForTime(e, route, startDistance, length, cfg.Value)
+	}
+	return 0, fmt.Errorf("unknown pacing mode: %s", cfg.Mode)
+}
+
+// SpawnPacer adds a pacer bot at startDistance. Pacers ride like any other
+// virtual rider (they can be drafted) but keep their configured name and pacing.
+func (g *Group) SpawnPacer(cfg PacerConfig, e *Engine, watts, startDistance float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	name := cfg.Name
+	if name == "" {
+		name = fmt.Sprintf("Pacer %d", g.nextID)
+	}
+	g.riders = append(g.riders, &VirtualRider{
+		ID:       g.nextID,
+		Name:     name,
+		Power:    watts,
+		Distance: startDistance,
+		Pacing:   cfg.Mode,
+		engine:   e,
+	})
+	g.nextID++
+}
+
+// Remove deletes one virtual rider by ID.
+func (g *Group) Remove(id int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for i, r := range g.riders {
+		if r.ID == id {
+			g.riders = append(g.riders[:i], g.riders[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sim
+
+import (
+	"fmt"
+	"math"
+)
+
+// Power strategies for route predictions and pacing plans
+const (
+	StrategyPower = "power" // Constant power (W)
+	StrategyFTP   = "ftp"   // Fraction of FTP (e.g. 0.75)
+	StrategyIF    = "if"    // Target intensity factor: NP = IF × FTP
+)
+
+const (
+	planSectionLength = 100.0 // m per pacing plan section
+	planMaxFactor     = 1.3   // Highest section power relative to the NP
+	planMaxGradeGain  = 0.15  // Highest extra power fraction per % of grade tried
+)
+
+// PacingStrategy is the power strategy a prediction or plan is based on.
+type PacingStrategy struct {
+	Mode  string  `json:"mode"`
+	Value float64 `json:"value"` // W, FTP fraction or IF, depending on Mode
+}
+
+// PlanSection is a stretch of the route ridden at one power.
+type PlanSection struct {
+	Start float64 `json:"start"` // m
+	End   float64 `json:"end"`   // m
+	Grade float64 `json:"grade"` // Average grade (%)
+	Power float64 `json:"power"` // Target (W)
+	Time  float64 `json:"time"`  // Predicted duration (s)
+	Speed float64 `json:"speed"` // Predicted average speed (km/h)
+}
+
+// PacingPlan is a predicted ride of the route section by section.
+type PacingPlan struct {
+	Variable bool          `json:"variable"` // Power follows the terrain (false = constant power)
+	Distance float64       `json:"distance"` // m
+	Time     float64       `json:"time"`     // Predicted finish time (s)
+	AvgPower float64       `json:"avg_power"`
+	NP       float64       `json:"np"`
+	AvgSpeed float64       `json:"avg_speed"` // km/h
+	Sections []PlanSection `json:"sections"`
+}
+
+// StrategyWatts resolves a strategy to the NP it asks for (constant power
+// strategies have NP = power).
+func StrategyWatts(s PacingStrategy, ftp float64) (float64, error) {
+	if s.Value <= 0 {
+		return 0, fmt.Errorf("strategy value must be positive")
+	}
+	switch s.Mode {
+	case StrategyPower:
+		return s.Value, nil
+	case StrategyFTP, StrategyIF:
+		if ftp <= 0 {
+			return 0, fmt.Errorf("FTP is not set")
+		}
+		return s.Value * ftp, nil
+	}
+	return 0, fmt.Errorf("unknown strategy: %s", s.Mode)
+}
+
+// PlanConstant predicts riding the whole route at constant power.
+func PlanConstant(e *Engine, route RouteLookup, length, watts float64) PacingPlan {
+	sections := planSections(route, length)
+	for i := range sections {
+		sections[i].Power = watts
+	}
+	return finishPlan(e, route, sections, false)
+}
+
+// PlanVariable finds the fastest plan with the given NP where power rises
+// with the grade: P = base × (1 + k × grade), between 0 and 130% of the NP.
+// Every k is tried with the base scaled to hit the NP, and the fastest wins.
+func PlanVariable(e *Engine, route RouteLookup, length, np float64) PacingPlan {
+	sections := planSections(route, length)
+	best := PlanConstant(e, route, length, np)
+	best.Variable = true
+
+	for k := 0.01; k <= planMaxGradeGain+1e-9; k += 0.01 {
+		low, high := 0.0, np*planMaxFactor
+		var plan PacingPlan
+		for i := 0; i < 25; i++ {
+			base := (low + high) / 2
+			for j := range sections {
+				sections[j].Power = math.Max(0, math.Min(base*(1+k*sections[j].Grade), np*planMaxFactor))
+			}
+			plan = finishPlan(e, route, sections, true)
+			if plan.NP > np {
+				high = base
+			} else {
+				low = base
+			}
+		}
+		if plan.Time < best.Time {
+			best = plan
+		}
+	}
+	return best
+}
+
+// planSections splits the route into sections with their average grade.
+func planSections(route RouteLookup, length float64) []PlanSection {
+	var sections []PlanSection
+	for start := 0.0; start < length; start += planSectionLength {
+		end := math.Min(start+planSectionLength, length)
+		sum, n := 0.0, 0
+		for d := start + predictionStep/2; d < end; d += predictionStep {
+			grade, _ := route(d)
+			sum += grade
+			n++
+		}
+		grade := 0.0
+		if n > 0 {
+			grade = sum / float64(n)
+		}
+		sections = append(sections, PlanSection{Start: start, End: end, Grade: grade})
+	}
+	return sections
+}
+
+// finishPlan predicts each section's time and the plan totals. NP is the
+// time-weighted fourth-power mean of the section powers, which is close to
+// the 30 s rolling NP since sections last longer than that window on most roads.
+func finishPlan(e *Engine, route RouteLookup, sections []PlanSection, variable bool) PacingPlan {
+	plan := PacingPlan{Variable: variable, Sections: make([]PlanSection, len(sections))}
+	energy, fourth := 0.0, 0.0
+	for i, s := range sections {
+		s.Time = PredictTime(e, route, s.Start, s.End-s.Start, s.Power)
+		if s.Time > 0 {
+			s.Speed = (s.End - s.Start) / s.Time * 3.6
+		}
+		plan.Sections[i] = s
+		plan.Time += s.Time
+		plan.Distance = s.End
+		energy += s.Power * s.Time
+		fourth += math.Pow(s.Power, 4) * s.Time
+	}
+	if plan.Time > 0 {
+		plan.AvgPower = energy / plan.Time
+		plan.NP = math.Pow(fourth/plan.Time, 0.25)
+		plan.AvgSpeed = plan.Distance / plan.Time * 3.6
+	}
+	return plan
+}
+
+// TargetAt returns the planned power at a route distance (0 outside the plan).
+func (p *PacingPlan) TargetAt(distance float64) float64 {
+	if len(p.Sections) == 0 || distance < 0 || distance >= p.Distance {
+		return 0
+	}
+	idx := int(distance / planSectionLength)
+	if idx >= len(p.Sections) {
+		idx = len(p.Sections) - 1
+	}
+	return p.Sections[idx].Power
+}
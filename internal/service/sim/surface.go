@@ -0,0 +1,38 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sim
+
+import "argus-cyclist/internal/domain"
+
+// surfaceCrrFactors scale the tyre's Crr (measured on smooth asphalt) per surface.
+var surfaceCrrFactors = map[string]float64{
+	domain.SurfaceAsphalt:  1.0,
+	domain.SurfaceConcrete: 1.15,
+	domain.SurfaceGravel:   1.8,
+	domain.SurfaceDirt:     2.4,
+	domain.SurfaceCobbles:  3.0,
+	domain.SurfaceGrass:    3.5,
+}
+
+// SurfaceCrrFactor returns the rolling resistance multiplier for a surface.
+// Unknown or empty surfaces ride like asphalt.
+func SurfaceCrrFactor(surface string) float64 {
+	if f, ok := surfaceCrrFactors[surface]; ok {
+		return f
+	}
+	return 1.0
+}
@@ -0,0 +1,110 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sim
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Wind modes
+const (
+	WindNone     = "none"
+	WindConstant = "constant" // Fixed speed and direction
+	WindGusty    = "gusty"    // Constant base wind plus periodic gusts
+	WindSchedule = "schedule" // Speed/direction change along the route distance
+)
+
+// WindScheduleEntry sets the wind from a route distance onwards.
+type WindScheduleEntry struct {
+	FromDistance float64 `json:"from_distance"` // m
+	Speed        float64 `json:"speed"`         // m/s
+	Direction    float64 `json:"direction"`     // Degrees the wind comes FROM (0 = north)
+}
+
+// WindModel describes the wind over the route.
+// Direction follows the meteorological convention: the bearing the wind blows from.
+type WindModel struct {
+	Mode       string              `json:"mode"`
+	Speed      float64             `json:"speed"`       // m/s
+	Direction  float64             `json:"direction"`   // Degrees
+	GustSpeed  float64             `json:"gust_speed"`  // Extra m/s at the peak of a gust
+	GustPeriod float64             `json:"gust_period"` // Seconds between gust peaks
+	Schedule   []WindScheduleEntry `json:"schedule"`
+}
+
+// Validate normalises the model and rejects impossible values.
+func (w *WindModel) Validate() error {
+	if w.Mode == "" {
+		w.Mode = WindNone
+	}
+	switch w.Mode {
+	case WindNone, WindConstant, WindGusty:
+	case WindSchedule:
+		if len(w.Schedule) == 0 {
+			return fmt.Errorf("wind schedule is empty")
+		}
+		sort.Slice(w.Schedule, func(i, j int) bool {
+			return w.Schedule[i].FromDistance < w.Schedule[j].FromDistance
+		})
+	default:
+		return fmt.Errorf("unknown wind mode: %s", w.Mode)
+	}
+	if w.Speed < 0 || w.GustSpeed < 0 {
+		return fmt.Errorf("wind speed cannot be negative")
+	}
+	if w.Mode == WindGusty && w.GustPeriod <= 0 {
+		w.GustPeriod = 20
+	}
+	return nil
+}
+
+// At returns the wind speed (m/s) and direction (degrees) at a route distance
+// and elapsed session time (s).
+func (w *WindModel) At(distance, elapsed float64) (float64, float64) {
+	if w == nil {
+		return 0, 0
+	}
+	switch w.Mode {
+	case WindConstant:
+		return w.Speed, w.Direction
+	case WindGusty:
+		// Two incommensurate sines give irregular but repeatable gusts (never below the base wind).
+		phase := 2 * math.Pi * elapsed / w.GustPeriod
+		gust := 0.5*(1+math.Sin(phase))*0.7 + 0.5*(1+math.Sin(phase*2.3+1.3))*0.3
+		return w.Speed + w.GustSpeed*gust, w.Direction
+	case WindSchedule:
+		entry := w.Schedule[0]
+		for _, e := range w.Schedule {
+			if e.FromDistance > distance {
+				break
+			}
+			entry = e
+		}
+		return entry.Speed, entry.Direction
+	}
+	return 0, 0
+}
+
+// WindComponents splits the wind into the component against the rider
+// (headwind, + slows the rider) and the crosswind (+ from the right), given
+// the rider's heading in degrees.
+func WindComponents(speed, fromDirection, heading float64) (headwind, crosswind float64) {
+	rel := (fromDirection - heading) * math.Pi / 180.0
+	return speed * math.Cos(rel), speed * math.Sin(rel)
+}
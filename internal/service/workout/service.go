@@ -19,7 +19,10 @@ package workout
 import (
 	"argus-cyclist/internal/domain"
 	"encoding/xml"
+	"fmt"
+	"math"
 	"os"
+	"strings"
 )
 
 type Service struct{}
@@ -28,7 +31,14 @@ func NewService() *Service {
 	return &Service{}
 }
 
-// LoadZWO reads the file and converts it to Argus' internal format
+// TextCue is a workout text event due at a point of the workout.
+type TextCue struct {
+	Segment int `json:"segment"`
+	domain.WorkoutTextEvent
+}
+
+// LoadZWO reads the file and converts it to Argus' internal format.
+// Anything that cannot be followed exactly is reported in the workout's Warnings.
 func (s *Service) LoadZWO(path string) (*domain.ActiveWorkout, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -41,64 +51,207 @@ func (s *Service) LoadZWO(path string) (*domain.ActiveWorkout, error) {
 	}
 
 	active := &domain.ActiveWorkout{
-		Metadata: zwo,
-		Segments: make([]domain.WorkoutSegment, 0),
+		Metadata:    zwo,
+		Segments:    make([]domain.WorkoutSegment, 0),
+		FTPOverride: zwo.FTPOverride,
+		Warnings:    make([]string, 0),
+	}
+	warn := func(format string, args ...any) {
+		active.Warnings = append(active.Warnings, fmt.Sprintf(format, args...))
 	}
 
-	idx := 0
-	totalTime := 0
+	if strings.EqualFold(zwo.DurationType, "distance") {
+		warn("Durations are in metres (durationType distance) and are ridden as seconds")
+	}
+	if zwo.SportType != "" && !strings.EqualFold(zwo.SportType, "bike") {
+		warn("Sport type %q: the workout is ridden as a bike workout", zwo.SportType)
+	}
+
+	add := func(seg domain.WorkoutSegment) {
+		seg.Index = len(active.Segments)
+		active.Segments = append(active.Segments, seg)
+		active.TotalDuration += seg.DurationSeconds
+	}
 
 	// Flattening logic
-	for _, step := range zwo.Workout.Steps {
+	for n, step := range zwo.Workout.Steps {
 		tagName := step.XMLName.Local
+		first := len(active.Segments)
+		duration := seconds(step.Duration)
+		cadenceLow, cadenceHigh := cadenceRange(step)
+
+		switch strings.ToLower(tagName) {
+		case "steadystate", "solidstate":
+			power := step.Power
+			if power == 0 && (step.PowerLow > 0 || step.PowerHigh > 0) {
+				power = (step.PowerLow + step.PowerHigh) / 2
+			}
+			add(domain.WorkoutSegment{
+				Type: "STEADY", DurationSeconds: duration,
+				StartFactor: power, EndFactor: power,
+				CadenceLow: cadenceLow, CadenceHigh: cadenceHigh,
+			})
+
+		case "freeride":
+			if step.FlatRoad != nil && *step.FlatRoad == 0 {
+				warn("Step %d: FlatRoad=\"0\" is not supported: free rides are held at a fixed 1%% grade, not the road grade", n+1)
+			}
+			add(domain.WorkoutSegment{
+				Type: "FREERIDE", DurationSeconds: duration, FreeRide: true,
+				CadenceLow: cadenceLow, CadenceHigh: cadenceHigh,
+			})
 
-		switch tagName {
-		case "SteadyState", "FreeRide":
-			active.Segments = append(active.Segments, domain.WorkoutSegment{
-				Index: idx, Type: "STEADY", DurationSeconds: step.Duration,
-				StartFactor: step.Power, EndFactor: step.Power,
+		case "maxeffort":
+			add(domain.WorkoutSegment{
+				Type: "MAX_EFFORT", DurationSeconds: duration, FreeRide: true,
+				Text: "Max effort!",
 			})
-			idx++
-			totalTime += step.Duration
 
-		case "Warmup", "Cooldown", "Ramp":
+		case "warmup", "cooldown", "ramp":
 			t := "RAMP"
-			if tagName == "Warmup" {
+			if strings.EqualFold(tagName, "Warmup") {
 				t = "WARMUP"
 			}
-			if tagName == "Cooldown" {
+			if strings.EqualFold(tagName, "Cooldown") {
 				t = "COOLDOWN"
 			}
+			low, high := step.PowerLow, step.PowerHigh
+			if low == 0 && high == 0 {
+				low, high = step.Power, step.Power
+			}
 
-			active.Segments = append(active.Segments, domain.WorkoutSegment{
-				Index: idx, Type: t, DurationSeconds: step.Duration,
-				StartFactor: step.PowerLow, EndFactor: step.PowerHigh,
+			add(domain.WorkoutSegment{
+				Type: t, DurationSeconds: duration,
+				StartFactor: low, EndFactor: high,
+				CadenceLow: cadenceLow, CadenceHigh: cadenceHigh,
 			})
-			idx++
-			totalTime += step.Duration
 
-		case "IntervalsT":
+		case "intervalst":
+			repeat := step.Repeat
+			if repeat <= 0 {
+				warn("Step %d: IntervalsT without Repeat is ridden once", n+1)
+				repeat = 1
+			}
+			onLow, onHigh := powerRange(step.OnPower, step.PowerOnLow, step.PowerOnHigh)
+			offLow, offHigh := powerRange(step.OffPower, step.PowerOffLow, step.PowerOffHigh)
+
 			// Expands repetitions into individual blocks
-			for i := 0; i < step.Repeat; i++ {
+			for i := 0; i < repeat; i++ {
 				// ON part
-				active.Segments = append(active.Segments, domain.WorkoutSegment{
-					Index: idx, Type: "INTERVAL_ON", DurationSeconds: step.OnDuration,
-					StartFactor: step.OnPower, EndFactor: step.OnPower,
+				add(domain.WorkoutSegment{
+					Type: "INTERVAL_ON", DurationSeconds: seconds(step.OnDuration),
+					StartFactor: onLow, EndFactor: onHigh,
+					CadenceLow: cadenceLow, CadenceHigh: cadenceHigh,
 				})
-				idx++
-				totalTime += step.OnDuration
 
 				// OFF part
-				active.Segments = append(active.Segments, domain.WorkoutSegment{
-					Index: idx, Type: "INTERVAL_OFF", DurationSeconds: step.OffDuration,
-					StartFactor: step.OffPower, EndFactor: step.OffPower,
+				add(domain.WorkoutSegment{
+					Type: "INTERVAL_OFF", DurationSeconds: seconds(step.OffDuration),
+					StartFactor: offLow, EndFactor: offHigh,
+					CadenceLow: step.CadenceResting, CadenceHigh: step.CadenceResting,
 				})
-				idx++
-				totalTime += step.OffDuration
 			}
+
+		default:
+			warn("Step %d: <%s> is not supported and was skipped", n+1, tagName)
+			continue
+		}
+
+		stepDuration := 0
+		for _, seg := range active.Segments[first:] {
+			stepDuration += seg.DurationSeconds
 		}
+		if stepDuration <= 0 {
+			warn("Step %d: <%s> has no duration", n+1, tagName)
+		}
+		attachTextEvents(active.Segments[first:], step.TextEvents, func(format string, args ...any) {
+			warn("Step %d: "+format, append([]any{n + 1}, args...)...)
+		})
 	}
 
-	active.TotalDuration = totalTime
 	return active, nil
-}
\ No newline at end of file
+}
+
+// attachTextEvents places a step's text events, timed from the step start,
+// on the segments the step was flattened into.
+func attachTextEvents(segments []domain.WorkoutSegment, events []domain.ZWOTextEvent, warn func(string, ...any)) {
+	for _, ev := range events {
+		if ev.Message == "" {
+			continue
+		}
+		if ev.TimeOffset == 0 && ev.DistOffset > 0 {
+			warn("text event %q uses a distance offset, which is not supported", ev.Message)
+			continue
+		}
+
+		offset := seconds(ev.TimeOffset)
+		placed := false
+		for i := range segments {
+			if offset < segments[i].DurationSeconds {
+				segments[i].TextEvents = append(segments[i].TextEvents, domain.WorkoutTextEvent{
+					Offset: offset, Message: ev.Message, Duration: seconds(ev.Duration),
+				})
+				placed = true
+				break
+			}
+			offset -= segments[i].DurationSeconds
+		}
+		if !placed {
+			warn("text event %q at %.0f s is past the end of the step", ev.Message, ev.TimeOffset)
+		}
+	}
+}
+
+// TextEventsBetween returns the text events due after from and up to to
+// (seconds of workout time), in order.
+func TextEventsBetween(w *domain.ActiveWorkout, from, to float64) []TextCue {
+	var cues []TextCue
+	start := 0.0
+	for _, seg := range w.Segments {
+		for _, ev := range seg.TextEvents {
+			at := start + float64(ev.Offset)
+			if at > from && at <= to {
+				cues = append(cues, TextCue{Segment: seg.Index, WorkoutTextEvent: ev})
+			}
+		}
+		start += float64(seg.DurationSeconds)
+		if start > to {
+			break
+		}
+	}
+	return cues
+}
+
+// cadenceRange returns a step's target cadence range (0, 0 = none).
+func cadenceRange(step domain.ZWOStep) (int, int) {
+	low, high := step.CadenceLow, step.CadenceHigh
+	if low == 0 && high == 0 {
+		return step.Cadence, step.Cadence
+	}
+	if low == 0 {
+		low = high
+	}
+	if high == 0 {
+		high = low
+	}
+	return low, high
+}
+
+// powerRange returns the start and end factors of an interval half: a ramp
+// from PowerOnLow/PowerOffLow to ...High when given, otherwise a steady power.
+func powerRange(power, low, high float64) (float64, float64) {
+	if low == 0 && high == 0 {
+		return power, power
+	}
+	if low == 0 {
+		low = high
+	}
+	if high == 0 {
+		high = low
+	}
+	return low, high
+}
+
+func seconds(v float64) int {
+	return int(math.Round(v))
+}
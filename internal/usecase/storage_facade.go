@@ -32,6 +32,8 @@ type StorageFacade struct {
 	EventRepo     domain.EventRepository
 	ComponentRepo domain.ComponentRepository
 	AIRepo        domain.AIRepository
+	RouteRepo     domain.RouteRepository
+	SegmentRepo   domain.SegmentRepository
 }
 
 func NewStorageFacade() *StorageFacade {
@@ -45,6 +47,8 @@ func NewStorageFacade() *StorageFacade {
 		EventRepo:     sqlite.NewEventRepository(state),
 		ComponentRepo: sqlite.NewComponentRepository(state),
 		AIRepo:        sqlite.NewAIRepository(state),
+		RouteRepo:     sqlite.NewRouteRepository(state),
+		SegmentRepo:   sqlite.NewSegmentRepository(state),
 	}
 }
 
@@ -64,6 +68,10 @@ func (s *StorageFacade) GetLocalAccounts() []domain.LocalAccount {
 	return s.ConnManager.GetLocalAccounts()
 }
 
+func (s *StorageFacade) GetAccountProfile(id string) (domain.UserProfile, error) {
+	return s.ConnManager.GetAccountProfile(id)
+}
+
 func (s *StorageFacade) CreateLocalAccount(acc domain.LocalAccount) error {
 	return s.ConnManager.CreateLocalAccount(acc)
 }
@@ -72,6 +80,14 @@ func (s *StorageFacade) DeleteLocalAccount(id string) error {
 	return s.ConnManager.DeleteLocalAccount(id)
 }
 
+func (s *StorageFacade) GetSegmentLeaderboard(segmentID uint, limit int) []domain.SegmentLeaderboardEntry {
+	return s.ConnManager.GetSegmentLeaderboard(segmentID, limit)
+}
+
+func (s *StorageFacade) DeleteSegmentEfforts(segmentID uint) error {
+	return s.ConnManager.DeleteSegmentEfforts(segmentID)
+}
+
 // ===============
 // User Repository
 // ===============
@@ -164,6 +180,82 @@ func (s *StorageFacade) UpdateActivityStatus(id uint, uploaded bool) error {
 	return s.ActivityRepo.UpdateActivityStatus(id, uploaded)
 }
 
+// ================
+// Route Repository
+// ================
+
+func (s *StorageFacade) SaveRoute(r *domain.Route) error {
+	return s.RouteRepo.SaveRoute(r)
+}
+
+func (s *StorageFacade) GetRoutes() ([]domain.Route, error) {
+	return s.RouteRepo.GetRoutes()
+}
+
+func (s *StorageFacade) SearchRoutes(query string) ([]domain.Route, error) {
+	return s.RouteRepo.SearchRoutes(query)
+}
+
+func (s *StorageFacade) GetRouteByID(id uint) (domain.Route, error) {
+	return s.RouteRepo.GetRouteByID(id)
+}
+
+func (s *StorageFacade) GetRouteBySource(source string) (domain.Route, error) {
+	return s.RouteRepo.GetRouteBySource(source)
+}
+
+func (s *StorageFacade) RenameRoute(id uint, name string) error {
+	return s.RouteRepo.RenameRoute(id, name)
+}
+
+func (s *StorageFacade) SetRouteMode(id uint, mode string) error {
+	return s.RouteRepo.SetRouteMode(id, mode)
+}
+
+func (s *StorageFacade) DeleteRoute(id uint) error {
+	return s.RouteRepo.DeleteRoute(id)
+}
+
+func (s *StorageFacade) GetRouteActivities(id uint) ([]domain.Activity, error) {
+	return s.RouteRepo.GetRouteActivities(id)
+}
+
+func (s *StorageFacade) RecordRouteRide(id uint, when time.Time, lapTime float64) error {
+	return s.RouteRepo.RecordRouteRide(id, when, lapTime)
+}
+
+// ==================
+// Segment Repository
+// ==================
+
+func (s *StorageFacade) SaveSegment(seg *domain.Segment) error {
+	return s.SegmentRepo.SaveSegment(seg)
+}
+
+func (s *StorageFacade) GetSegmentsForRoute(routeSource string) ([]domain.Segment, error) {
+	return s.SegmentRepo.GetSegmentsForRoute(routeSource)
+}
+
+func (s *StorageFacade) GetSegmentByID(id uint) (domain.Segment, error) {
+	return s.SegmentRepo.GetSegmentByID(id)
+}
+
+func (s *StorageFacade) DeleteSegment(id uint) error {
+	return s.SegmentRepo.DeleteSegment(id)
+}
+
+func (s *StorageFacade) SaveSegmentEffort(e *domain.SegmentEffort) error {
+	return s.SegmentRepo.SaveSegmentEffort(e)
+}
+
+func (s *StorageFacade) GetSegmentEfforts(segmentID uint) ([]domain.SegmentEffort, error) {
+	return s.SegmentRepo.GetSegmentEfforts(segmentID)
+}
+
+func (s *StorageFacade) GetBestSegmentEffort(segmentID uint) (domain.SegmentEffort, error) {
+	return s.SegmentRepo.GetBestSegmentEffort(segmentID)
+}
+
 // ================
 // Power Repository
 // ================
@@ -0,0 +1,147 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"argus-cyclist/internal/domain"
+	"argus-cyclist/internal/service/gpx"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// lapTracker accumulates the stats of the lap in progress.
+type lapTracker struct {
+	startTime   time.Time
+	startActive float64
+	startDist   float64
+	startGain   float64
+
+	ticks      int
+	powerSum   int
+	cadenceSum int
+	hrSum      int
+	hrTicks    int
+	maxPower   int
+	maxHR      int
+	maxSpeed   float64
+}
+
+// reset starts a new lap at the given session state.
+func (l *lapTracker) reset(now time.Time, activeTime, distance, elevationGain float64) {
+	*l = lapTracker{startTime: now, startActive: activeTime, startDist: distance, startGain: elevationGain}
+}
+
+// sample adds one telemetry tick to the lap.
+func (l *lapTracker) sample(power int16, hr, cadence uint8, speedKmh float64) {
+	l.ticks++
+	l.powerSum += int(power)
+	l.cadenceSum += int(cadence)
+	if int(power) > l.maxPower {
+		l.maxPower = int(power)
+	}
+	if hr > 0 {
+		l.hrSum += int(hr)
+		l.hrTicks++
+		if int(hr) > l.maxHR {
+			l.maxHR = int(hr)
+		}
+	}
+	if speedKmh > l.maxSpeed {
+		l.maxSpeed = speedKmh
+	}
+}
+
+// close builds the summary of the lap ending at the given session state.
+func (l *lapTracker) close(index int, now time.Time, activeTime, distance, elevationGain float64, complete bool) domain.LapStats {
+	lap := domain.LapStats{
+		Index:         index,
+		StartTime:     l.startTime,
+		EndTime:       now,
+		Duration:      activeTime - l.startActive,
+		SplitTime:     activeTime,
+		Distance:      distance - l.startDist,
+		MaxPower:      l.maxPower,
+		MaxHR:         l.maxHR,
+		MaxSpeed:      l.maxSpeed,
+		ElevationGain: elevationGain - l.startGain,
+		Complete:      complete,
+	}
+	if l.ticks > 0 {
+		lap.AvgPower = l.powerSum / l.ticks
+		lap.AvgCadence = l.cadenceSum / l.ticks
+	}
+	if l.hrTicks > 0 {
+		lap.AvgHR = l.hrSum / l.hrTicks
+	}
+	if lap.Duration > 0 {
+		lap.AvgSpeed = lap.Distance / lap.Duration * 3.6
+	}
+	return lap
+}
+
+// completeLap closes the lap in progress, records it in the FIT file and starts the next one.
+func (a *App) completeLap(now time.Time, complete bool) domain.LapStats {
+	lap := a.lap.close(len(a.laps)+1, now, a.sessionActiveTime, a.currentDist, a.sessionElevationGain, complete)
+	a.laps = append(a.laps, lap)
+	a.fitService.AddLap(lap)
+	a.lap.reset(now, a.sessionActiveTime, a.currentDist, a.sessionElevationGain)
+	return lap
+}
+
+// coursePoint locates a ridden distance on the route according to the route mode.
+func (a *App) coursePoint(distance, totalRouteDistance float64) (domain.RoutePoint, gpx.CoursePosition) {
+	c := gpx.Locate(a.routeMode, distance, totalRouteDistance)
+	return a.gpxService.GetCoursePoint(c), c
+}
+
+// ==========
+// ROUTE MODE
+// ==========
+
+// SetRouteMode selects what happens at the end of the route: "loop", "finish"
+// or "out_and_back". The mode is saved with the library route and restored
+// when it loads again. It cannot change during a session, as the laps ridden
+// so far depend on it.
+func (a *App) SetRouteMode(mode string) error {
+	if err := gpx.ValidateCourseMode(mode); err != nil {
+		return err
+	}
+	if a.isRecording {
+		return fmt.Errorf("the route mode cannot change during a session")
+	}
+	if a.currentRouteID != 0 {
+		if err := a.storageService.SetRouteMode(a.currentRouteID, mode); err != nil {
+			return err
+		}
+	}
+	a.routeMode = mode
+	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Route mode: %s", mode))
+	return nil
+}
+
+// GetRouteMode returns the current route mode.
+func (a *App) GetRouteMode() string {
+	return a.routeMode
+}
+
+// GetLaps returns the laps completed in the current session.
+func (a *App) GetLaps() []domain.LapStats {
+	return append([]domain.LapStats{}, a.laps...)
+}
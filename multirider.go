@@ -0,0 +1,520 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"argus-cyclist/internal/domain"
+	"argus-cyclist/internal/service/ble"
+	"argus-cyclist/internal/service/fit"
+	"argus-cyclist/internal/service/sim"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// riderSlot binds one trainer/HR pair to a rider profile.
+// Every slot owns its own physics engine, trainer control and FIT recording,
+// so N riders can race head-to-head on the same route from one machine.
+type riderSlot struct {
+	id        int
+	profileID string
+	profile   domain.UserProfile
+
+	trainer          domain.TrainerService
+	engine           *sim.Engine
+	fitService       *fit.Service
+	telemetryChan    chan domain.Telemetry
+	trainerConnected bool
+	hrConnected      bool
+	isVirtual        bool
+
+	distance      float64
+	activeTime    float64
+	elevationGain float64
+	powerSum      uint64
+	ticks         int
+	last          domain.Telemetry
+}
+
+// multiRiderSession holds the riders of a head-to-head session.
+// Each rider loop runs in its own goroutine, so all access goes through mu.
+type multiRiderSession struct {
+	mu        sync.Mutex
+	riders    []*riderSlot
+	nextID    int
+	isRunning bool
+	cancel    context.CancelFunc
+	startTime time.Time
+}
+
+func newMultiRiderSession() *multiRiderSession {
+	return &multiRiderSession{nextID: 1}
+}
+
+// RiderStatus is the frontend view of a rider slot.
+type RiderStatus struct {
+	ID               int              `json:"id"`
+	ProfileID        string           `json:"profile_id"`
+	Name             string           `json:"name"`
+	TrainerConnected bool             `json:"trainer_connected"`
+	HRConnected      bool             `json:"hr_connected"`
+	IsVirtual        bool             `json:"is_virtual"`
+	Distance         float64          `json:"distance"`
+	Duration         float64          `json:"duration"`
+	AvgPower         int              `json:"avg_power"`
+	ElevationGain    float64          `json:"elevation_gain"`
+	Telemetry        domain.Telemetry `json:"telemetry"`
+}
+
+// RiderTelemetry is emitted as "rider_telemetry" for every rider sample.
+type RiderTelemetry struct {
+	RiderID   int              `json:"rider_id"`
+	Name      string           `json:"name"`
+	Telemetry domain.Telemetry `json:"telemetry"`
+}
+
+// RiderScore is the event score the frontend computed for one rider.
+type RiderScore struct {
+	RiderID int     `json:"rider_id"`
+	Score   float64 `json:"score"`
+	Status  string  `json:"status"` // "success", "failed"
+}
+
+func (r *riderSlot) status() RiderStatus {
+	avgPower := 0
+	if r.ticks > 0 {
+		avgPower = int(r.powerSum) / r.ticks
+	}
+	return RiderStatus{
+		ID:               r.id,
+		ProfileID:        r.profileID,
+		Name:             r.profile.Name,
+		TrainerConnected: r.trainerConnected,
+		HRConnected:      r.hrConnected,
+		IsVirtual:        r.isVirtual,
+		Distance:         r.distance,
+		Duration:         r.activeTime,
+		AvgPower:         avgPower,
+		ElevationGain:    r.elevationGain,
+		Telemetry:        r.last,
+	}
+}
+
+func (m *multiRiderSession) find(id int) (*riderSlot, error) {
+	for _, r := range m.riders {
+		if r.id == id {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("rider %d not found", id)
+}
+
+// ===================
+// MULTI-RIDER SESSION
+// ===================
+
+// AddRider creates a new rider slot bound to a local account profile.
+func (a *App) AddRider(profileID string) (RiderStatus, error) {
+	profile, err := a.storageService.GetAccountProfile(profileID)
+	if err != nil {
+		return RiderStatus{}, fmt.Errorf("failed to load rider profile: %v", err)
+	}
+
+	m := a.multiRider
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.isRunning {
+		return RiderStatus{}, fmt.Errorf("cannot add riders while a session is running")
+	}
+
+	engine := sim.NewEngine(profile.Weight, profile.BikeWeight)
+	configureEngine(engine, profile)
+
+	r := &riderSlot{
+		id:            m.nextID,
+		profileID:     profileID,
+		profile:       profile,
+		trainer:       ble.NewRealService(),
+		engine:        engine,
+		fitService:    fit.NewService(),
+		telemetryChan: make(chan domain.Telemetry),
+	}
+	m.nextID++
+	m.riders = append(m.riders, r)
+
+	return r.status(), nil
+}
+
+// RemoveRider disconnects the rider's devices and drops the slot.
+func (a *App) RemoveRider(riderID int) error {
+	m := a.multiRider
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.isRunning {
+		return fmt.Errorf("cannot remove riders while a session is running")
+	}
+
+	for i, r := range m.riders {
+		if r.id == riderID {
+			r.trainer.Disconnect()
+			m.riders = append(m.riders[:i], m.riders[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("rider %d not found", riderID)
+}
+
+// GetRiders returns the current state of every rider slot.
+func (a *App) GetRiders() []RiderStatus {
+	m := a.multiRider
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	riders := []RiderStatus{}
+	for _, r := range m.riders {
+		riders = append(riders, r.status())
+	}
+	return riders
+}
+
+// riderStatusCallback tags BLE status messages with the rider they belong to.
+func (a *App) riderStatusCallback(riderID int) func(string, string) {
+	return func(stage string, data string) {
+		runtime.EventsEmit(a.ctx, "ble_connection_status", map[string]interface{}{"stage": stage, "msg": data, "rider_id": riderID})
+	}
+}
+
+// ConnectRiderTrainer connects a real trainer to the given rider slot.
+func (a *App) ConnectRiderTrainer(riderID int, macAddress string) (string, error) {
+	a.StopDeviceDiscovery()
+
+	m := a.multiRider
+	m.mu.Lock()
+	r, err := m.find(riderID)
+	if err != nil {
+		m.mu.Unlock()
+		return "Trainer Error", err
+	}
+	if m.isRunning {
+		m.mu.Unlock()
+		return "Trainer Error", fmt.Errorf("cannot change trainers while a session is running")
+	}
+	if r.trainerConnected {
+		r.trainer.Disconnect()
+		r.trainerConnected = false
+	}
+	if _, isReal := r.trainer.(*ble.RealService); !isReal {
+		r.trainer = ble.NewRealService()
+	}
+	trainer := r.trainer
+	m.mu.Unlock()
+
+	if err := trainer.ConnectTrainer(macAddress, a.riderStatusCallback(riderID)); err != nil {
+		return "Trainer Error", err
+	}
+
+	m.mu.Lock()
+	r.trainerConnected = true
+	r.isVirtual = false
+	m.mu.Unlock()
+	return "Trainer Connected", nil
+}
+
+// ConnectRiderVirtualTrainer attaches a simulated trainer to the given rider slot.
+func (a *App) ConnectRiderVirtualTrainer(riderID int) (string, error) {
+	m := a.multiRider
+	m.mu.Lock()
+	r, err := m.find(riderID)
+	if err != nil {
+		m.mu.Unlock()
+		return "Simulator Error", err
+	}
+	if m.isRunning {
+		m.mu.Unlock()
+		return "Simulator Error", fmt.Errorf("cannot change trainers while a session is running")
+	}
+	if r.trainerConnected {
+		r.trainer.Disconnect()
+		r.trainerConnected = false
+	}
+	r.trainer = ble.NewMockService()
+	trainer := r.trainer
+	m.mu.Unlock()
+
+	if err := trainer.ConnectTrainer("", a.riderStatusCallback(riderID)); err != nil {
+		return "Simulator Error", err
+	}
+
+	m.mu.Lock()
+	r.trainerConnected = true
+	r.isVirtual = true
+	m.mu.Unlock()
+	return "Simulator Active", nil
+}
+
+// ConnectRiderHeartRate connects an HR monitor to the given rider slot.
+func (a *App) ConnectRiderHeartRate(riderID int, macAddress string) (string, error) {
+	a.StopDeviceDiscovery()
+
+	m := a.multiRider
+	m.mu.Lock()
+	r, err := m.find(riderID)
+	if err != nil {
+		m.mu.Unlock()
+		return "HR Error", err
+	}
+	if r.hrConnected {
+		m.mu.Unlock()
+		return "HR Already Connected", nil
+	}
+	trainer := r.trainer
+	m.mu.Unlock()
+
+	if err := trainer.ConnectHR(macAddress, a.riderStatusCallback(riderID)); err != nil {
+		return "HR Error", err
+	}
+
+	m.mu.Lock()
+	r.hrConnected = true
+	if m.isRunning {
+		r.trainer.SubscribeStats(r.telemetryChan)
+	}
+	m.mu.Unlock()
+	return "HR Monitor Connected", nil
+}
+
+// StartMultiRiderSession starts one independent game loop per rider on the current route.
+func (a *App) StartMultiRiderSession() (string, error) {
+	if a.isRecording {
+		return "", fmt.Errorf("a single-rider session is already running")
+	}
+
+	m := a.multiRider
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.isRunning {
+		return "", fmt.Errorf("multi-rider session already running")
+	}
+	if len(m.riders) == 0 {
+		return "", fmt.Errorf("no riders added")
+	}
+	for _, r := range m.riders {
+		if !r.trainerConnected {
+			return "", fmt.Errorf("rider %s has no trainer connected", r.profile.Name)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.startTime = time.Now()
+
+	for i, r := range m.riders {
+		r.distance = 0
+		r.activeTime = 0
+		r.elevationGain = 0
+		r.powerSum = 0
+		r.ticks = 0
+		r.last = domain.Telemetry{}
+		r.engine.Reset()
+		r.fitService.StartSession(m.startTime)
+
+		if err := r.trainer.SubscribeStats(r.telemetryChan); err != nil {
+			for _, subscribed := range m.riders[:i] {
+				subscribed.trainer.UnsubscribeStats()
+			}
+			cancel()
+			return "", fmt.Errorf("rider %s: %v", r.profile.Name, err)
+		}
+	}
+
+	for _, r := range m.riders {
+		go a.riderLoop(ctx, r, r.trainer)
+	}
+	m.isRunning = true
+
+	runtime.EventsEmit(a.ctx, "status_change", "RECORDING")
+	return "Started", nil
+}
+
+// StopMultiRiderSession stops every rider loop but keeps the recorded data
+// so the event results can still be saved.
+func (a *App) StopMultiRiderSession() []RiderStatus {
+	m := a.multiRider
+	m.mu.Lock()
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.isRunning = false
+	for _, r := range m.riders {
+		r.trainer.UnsubscribeStats()
+	}
+	m.mu.Unlock()
+
+	runtime.EventsEmit(a.ctx, "status_change", "IDLE")
+	return a.GetRiders()
+}
+
+// SaveMultiRiderEventResults saves one EventRecord (and FIT file) per rider.
+func (a *App) SaveMultiRiderEventResults(mode string, results []RiderScore) error {
+	m := a.multiRider
+	m.mu.Lock()
+	if m.isRunning {
+		m.mu.Unlock()
+		a.StopMultiRiderSession()
+		m.mu.Lock()
+	}
+	defer m.mu.Unlock()
+
+	var firstErr error
+	for _, res := range results {
+		r, err := m.find(res.RiderID)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err := a.saveEventRecord(r.fitService, r.profile.Name, mode, res.Score, res.Status); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// riderLoop is the per-rider counterpart of gameLoop: it applies the rider's
+// own physics, drives the rider's trainer (captured at session start) and records the rider's FIT file.
+func (a *App) riderLoop(ctx context.Context, r *riderSlot, trainer domain.TrainerService) {
+	m := a.multiRider
+	lastUpdate := time.Now()
+	var currentPower int16 = 0
+	var currentHR uint8 = 0
+	var currentCadence uint8 = 0
+
+	lastPowerTime := time.Now()
+	lastHRTime := time.Now()
+	sensorTimeout := 5 * time.Second
+	totalRouteDistance := a.gpxService.GetTotalDistance()
+
+	lastSentGrade := -999.0
+	lastWind := 0.0
+	lastCrr := 0.0
+	lastAltitude := -9999.0
+
+	trainer.SetTrainerMode("SIM")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case rawData := <-r.telemetryChan:
+			if rawData.Power != -1 {
+				currentPower = rawData.Power
+				if rawData.Cadence > 0 {
+					currentCadence = rawData.Cadence
+				} else if currentPower == 0 {
+					currentCadence = 0
+				}
+				lastPowerTime = time.Now()
+			}
+			if rawData.HeartRate > 0 {
+				currentHR = rawData.HeartRate
+				lastHRTime = time.Now()
+			}
+
+			now := time.Now()
+			dt := now.Sub(lastUpdate).Seconds()
+			lastUpdate = now
+
+			if now.Sub(lastPowerTime) > sensorTimeout {
+				currentPower = 0
+				currentCadence = 0
+			}
+			if now.Sub(lastHRTime) > sensorTimeout {
+				currentHR = 0
+			}
+
+			m.mu.Lock()
+			distance := r.distance
+			m.mu.Unlock()
+			routePoint, course := a.coursePoint(distance, totalRouteDistance)
+
+			activeGrade := routePoint.Grade
+			if a.currentDirectGrade != 0 {
+				activeGrade = a.currentDirectGrade
+			}
+			if math.Abs(activeGrade-lastSentGrade) > 0.1 {
+				trainer.SetGrade(activeGrade)
+				lastSentGrade = activeGrade
+			}
+
+			m.mu.Lock()
+			activeTime := r.activeTime
+			m.mu.Unlock()
+			headwind, crosswind := a.windAt(course, activeTime)
+			r.engine.Headwind = headwind
+			r.engine.Surface = routePoint.Surface
+			if math.Abs(headwind-lastWind) > 0.3 || r.engine.RollingCrr() != lastCrr {
+				trainer.SetSimulationParams(domain.SimulationParams{
+					WindResistance: r.engine.CdA * r.engine.AirDensity(routePoint.Elevation),
+					DraftingFactor: 1,
+					WindSpeed:      headwind,
+					Crr:            r.engine.RollingCrr(),
+				})
+				lastWind = headwind
+				lastCrr = r.engine.RollingCrr()
+			}
+
+			speedMs := r.engine.Step(float64(currentPower), activeGrade, routePoint.Elevation, dt)
+
+			m.mu.Lock()
+			r.distance += speedMs * dt
+			r.activeTime += dt
+			r.powerSum += uint64(currentPower)
+			r.ticks++
+			if lastAltitude != -9999.0 && routePoint.Elevation > lastAltitude {
+				r.elevationGain += routePoint.Elevation - lastAltitude
+			}
+			lastAltitude = routePoint.Elevation
+
+			t := domain.Telemetry{
+				Timestamp: now, Power: currentPower, Cadence: currentCadence, HeartRate: currentHR,
+				Speed: speedMs * 3.6, TotalDistance: r.distance, CurrentGrade: routePoint.Grade,
+				Latitude: routePoint.Latitude, Longitude: routePoint.Longitude, Altitude: routePoint.Elevation,
+				ElevationGain: r.elevationGain,
+				RiderWeight:   r.engine.UserWeight,
+				Headwind:      headwind,
+				Crosswind:     crosswind,
+				Surface:       surfaceName(routePoint.Surface),
+			}
+			r.last = t
+			name := r.profile.Name
+			m.mu.Unlock()
+
+			r.fitService.AddRecord(t)
+			runtime.EventsEmit(a.ctx, "rider_telemetry", RiderTelemetry{RiderID: r.id, Name: name, Telemetry: t})
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	"argus-cyclist/internal/service/sim"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// planInputs returns a prediction engine set up like the rider's, the route
+// lookup, its length, and the NP the strategy asks for.
+func (a *App) planInputs(strategy sim.PacingStrategy) (*sim.Engine, sim.RouteLookup, float64, float64, error) {
+	total := a.gpxService.GetTotalDistance()
+	if total <= 0 {
+		return nil, nil, 0, 0, fmt.Errorf("no route loaded")
+	}
+	profile := a.GetUserProfile()
+	np, err := sim.StrategyWatts(strategy, float64(profile.FTP))
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	e := sim.NewEngine(profile.Weight, profile.BikeWeight)
+	configureEngine(e, profile)
+	return e, a.routeLookup(total), total, np, nil
+}
+
+// PredictRouteTime estimates the finish time of one lap of the route at the
+// constant power of the strategy ("power" W, "ftp" fraction or "if" intensity factor).
+func (a *App) PredictRouteTime(strategy sim.PacingStrategy) (sim.PacingPlan, error) {
+	e, route, total, watts, err := a.planInputs(strategy)
+	if err != nil {
+		return sim.PacingPlan{}, err
+	}
+	return sim.PlanConstant(e, route, total, watts), nil
+}
+
+// CreatePacingPlan builds a variable pacing plan with the same NP as the
+// strategy (harder on climbs, easier on descents) and shows it as the
+// target-power overlay during the ride.
+func (a *App) CreatePacingPlan(strategy sim.PacingStrategy) (*sim.PacingPlan, error) {
+	e, route, total, np, err := a.planInputs(strategy)
+	if err != nil {
+		return nil, err
+	}
+	constant := sim.PlanConstant(e, route, total, np)
+	plan := sim.PlanVariable(e, route, total, np)
+	a.pacingPlan = &plan
+	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Pacing plan: NP %.0f W, %.0f s faster than constant power", plan.NP, constant.Time-plan.Time))
+	return a.pacingPlan, nil
+}
+
+// GetPacingPlan returns the active pacing plan (nil if none).
+func (a *App) GetPacingPlan() *sim.PacingPlan {
+	return a.pacingPlan
+}
+
+// ClearPacingPlan removes the target-power overlay.
+func (a *App) ClearPacingPlan() {
+	a.pacingPlan = nil
+}
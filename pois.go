@@ -0,0 +1,96 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"time"
+
+	"argus-cyclist/internal/service/gpx"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// poiApproachDistance is how far ahead (m) a point of interest is announced.
+const poiApproachDistance = 500.0
+
+// POIEvent is emitted as "poi_approaching" and "poi_reached".
+type POIEvent struct {
+	Index    int             `json:"index"`
+	POI      gpx.CoursePoint `json:"poi"`
+	Distance float64         `json:"distance"` // Metres to go (0 when reached)
+}
+
+// poiTracker remembers which points of interest were announced on the
+// current pass over the route (one per lap and riding direction).
+type poiTracker struct {
+	pass       int
+	ahead      map[int]bool // Seen ahead of the rider on this pass
+	approached map[int]bool
+	reached    map[int]bool
+}
+
+func newPOITracker() poiTracker {
+	return poiTracker{pass: -1}
+}
+
+// checkPOIs announces the points of interest the rider is approaching or
+// has just passed, recording each pass as a FIT course point event.
+func (a *App) checkPOIs(c gpx.CoursePosition, now time.Time) {
+	pois := a.gpxService.GetCoursePoints()
+	if len(pois) == 0 {
+		return
+	}
+
+	pass := c.Lap * 2
+	if c.Reversed {
+		pass++
+	}
+	if pass != a.pois.pass {
+		// Points still ahead when the pass ended lay between the last
+		// position and the route end (lap wrap or out-and-back turn).
+		for i := range pois {
+			if a.pois.ahead[i] && !a.pois.reached[i] {
+				a.reachPOI(i, pois[i], now)
+			}
+		}
+		a.pois = poiTracker{pass: pass, ahead: map[int]bool{}, approached: map[int]bool{}, reached: map[int]bool{}}
+	}
+
+	for i, poi := range pois {
+		togo := poi.Distance - c.Position
+		if c.Reversed {
+			togo = -togo
+		}
+		if togo > 0 {
+			a.pois.ahead[i] = true
+			if togo <= poiApproachDistance && !a.pois.approached[i] {
+				a.pois.approached[i] = true
+				runtime.EventsEmit(a.ctx, "poi_approaching", POIEvent{Index: i, POI: poi, Distance: togo})
+			}
+		} else if a.pois.ahead[i] && !a.pois.reached[i] {
+			a.reachPOI(i, poi, now)
+		}
+	}
+}
+
+func (a *App) reachPOI(i int, poi gpx.CoursePoint, now time.Time) {
+	a.pois.reached[i] = true
+	if a.fitService != nil {
+		a.fitService.AddCoursePointEvent(i, now)
+	}
+	runtime.EventsEmit(a.ctx, "poi_reached", POIEvent{Index: i, POI: poi})
+}
@@ -0,0 +1,264 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"argus-cyclist/internal/domain"
+	"argus-cyclist/internal/service/gpx"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// routeThumbnailPoints is the size of the simplified polyline kept for the library list.
+const routeThumbnailPoints = 100
+
+// registerRoute adds the route loaded in svc to the library, or refreshes its
+// stats if the file is already there (name and ride history are kept).
+func (a *App) registerRoute(path string, svc *gpx.Service) (domain.Route, error) {
+	source, err := filepath.Abs(path)
+	if err != nil {
+		source = path
+	}
+
+	route, err := a.storageService.GetRouteBySource(source)
+	if err != nil {
+		route = domain.Route{
+			Name:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			Source: source,
+		}
+	}
+
+	points := svc.GetAllPoints()
+	route.Distance = svc.GetTotalDistance()
+	route.ElevationGain = 0
+	for i := 1; i < len(points); i++ {
+		if d := points[i].Elevation - points[i-1].Elevation; d > 0 {
+			route.ElevationGain += d
+		}
+	}
+	route.Climbs = len(svc.GetClimbs())
+	route.Thumbnail = routeThumbnail(points)
+
+	if err := a.storageService.SaveRoute(&route); err != nil {
+		return route, err
+	}
+	return route, nil
+}
+
+// addRouteToLibrary loads a route file on its own (without replacing the
+// current route) and registers it.
+func (a *App) addRouteToLibrary(path string) {
+	svc := gpx.NewService()
+	if _, err := svc.LoadAndProcess(path); err != nil {
+		fmt.Printf("[GPX] Route not added to the library: %v\n", err)
+		return
+	}
+	if _, err := a.registerRoute(path, svc); err != nil {
+		fmt.Printf("[GPX] Route not added to the library: %v\n", err)
+	}
+}
+
+// routeThumbnail keeps evenly spaced [lat, lon] pairs for drawing a preview.
+func routeThumbnail(points []domain.RoutePoint) [][2]float64 {
+	if len(points) == 0 {
+		return nil
+	}
+	step := max(1, len(points)/routeThumbnailPoints)
+	thumb := make([][2]float64, 0, routeThumbnailPoints+1)
+	for i := 0; i < len(points); i += step {
+		thumb = append(thumb, [2]float64{points[i].Latitude, points[i].Longitude})
+	}
+	if last := points[len(points)-1]; (len(points)-1)%step != 0 {
+		thumb = append(thumb, [2]float64{last.Latitude, last.Longitude})
+	}
+	return thumb
+}
+
+// recordRouteRide updates the library stats of the route just ridden. Only
+// full laps of a loop or finish route count towards the personal best.
+func (a *App) recordRouteRide(when time.Time) {
+	if a.currentRouteID == 0 {
+		return
+	}
+	best := 0.0
+	if a.routeMode != gpx.CourseOutAndBack {
+		for _, lap := range a.laps {
+			if lap.Complete && (best == 0 || lap.Duration < best) {
+				best = lap.Duration
+			}
+		}
+	}
+	if err := a.storageService.RecordRouteRide(a.currentRouteID, when, best); err != nil {
+		fmt.Printf("[GPX] Route stats not updated: %v\n", err)
+	}
+}
+
+// GetRouteLibrary lists the routes of the library, most recently ridden first.
+func (a *App) GetRouteLibrary() []domain.Route {
+	routes, err := a.storageService.GetRoutes()
+	if err != nil {
+		fmt.Printf("[GPX] Error reading the route library: %v\n", err)
+	}
+	return routes
+}
+
+// SearchRouteLibrary finds routes whose name or file contains the query.
+func (a *App) SearchRouteLibrary(query string) []domain.Route {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return a.GetRouteLibrary()
+	}
+	routes, err := a.storageService.SearchRoutes(query)
+	if err != nil {
+		fmt.Printf("[GPX] Error searching the route library: %v\n", err)
+	}
+	return routes
+}
+
+// RenameLibraryRoute changes the display name of a route (the file is not renamed).
+func (a *App) RenameLibraryRoute(id uint, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("the route name cannot be empty")
+	}
+	return a.storageService.RenameRoute(id, name)
+}
+
+// DeleteLibraryRoute removes a route from the library. The route file and the
+// activities ridden on it are kept.
+func (a *App) DeleteLibraryRoute(id uint) error {
+	if err := a.storageService.DeleteRoute(id); err != nil {
+		return err
+	}
+	if a.currentRouteID == id {
+		a.currentRouteID = 0
+	}
+	return nil
+}
+
+// LoadLibraryRoute loads a library route as the current route.
+func (a *App) LoadLibraryRoute(id uint) string {
+	route, err := a.storageService.GetRouteByID(id)
+	if err != nil {
+		runtime.EventsEmit(a.ctx, "error", fmt.Sprintf("Route error: %v", err))
+		return ""
+	}
+	return a.loadRouteFile(route.Source)
+}
+
+// GetRouteActivities returns the activities ridden on a library route, oldest first.
+func (a *App) GetRouteActivities(id uint) []domain.Activity {
+	activities, err := a.storageService.GetRouteActivities(id)
+	if err != nil {
+		return nil
+	}
+	return activities
+}
+
+// routeBaseName is the current route name without a file extension.
+func (a *App) routeBaseName() string {
+	return strings.TrimSuffix(a.currentRouteName, filepath.Ext(a.currentRouteName))
+}
+
+// setEditedRoute makes an edited point set (slice, reversal, chain) the current route.
+// It is a new route: save it with ExportRouteGPX to keep it.
+func (a *App) setEditedRoute(name string) string {
+	a.currentRouteName = name
+	a.currentRouteID = 0
+	a.routeMode = gpx.CourseLoop
+	a.ghost = nil
+	a.pacingPlan = nil
+	runtime.EventsEmit(a.ctx, "log", fmt.Sprintf("Route: %s | %d points | %.2f km", name, len(a.gpxService.GetAllPoints()), a.gpxService.GetTotalDistance()/1000))
+	return name
+}
+
+// SliceRoute keeps only the part of the current route between two distances (m).
+func (a *App) SliceRoute(start, end float64) (string, error) {
+	if err := a.gpxService.Slice(start, end); err != nil {
+		return "", err
+	}
+	return a.setEditedRoute(fmt.Sprintf("%s (%.0f-%.0f m)", a.routeBaseName(), start, end)), nil
+}
+
+// ReverseRoute turns the current route around.
+func (a *App) ReverseRoute() (string, error) {
+	if err := a.gpxService.Reverse(); err != nil {
+		return "", err
+	}
+	return a.setEditedRoute(a.routeBaseName() + " (reversed)"), nil
+}
+
+// ChainLibraryRoutes loads several library routes, in the given order, as one route.
+func (a *App) ChainLibraryRoutes(ids []uint) (string, error) {
+	paths := make([]string, 0, len(ids))
+	for _, id := range ids {
+		route, err := a.storageService.GetRouteByID(id)
+		if err != nil {
+			return "", err
+		}
+		paths = append(paths, route.Source)
+	}
+	return a.chainRouteFiles(paths)
+}
+
+// SelectRoutesToChain opens a file dialog and loads the selected route files as one route.
+func (a *App) SelectRoutesToChain() (string, error) {
+	selection, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Select the Routes to Chain", Filters: []runtime.FileFilter{{DisplayName: "Route files (GPX, TCX, FIT, GeoJSON, KML)", Pattern: "*.gpx;*.tcx;*.fit;*.geojson;*.json;*.kml"}},
+	})
+	if err != nil || len(selection) == 0 {
+		return "", err
+	}
+	return a.chainRouteFiles(selection)
+}
+
+// chainRouteFiles loads the first file as the current route and appends the others.
+func (a *App) chainRouteFiles(paths []string) (string, error) {
+	if len(paths) < 2 {
+		return "", fmt.Errorf("select at least two routes to chain")
+	}
+
+	src := a.elevationSource(false)
+	var others []*gpx.Service
+	names := []string{strings.TrimSuffix(filepath.Base(paths[0]), filepath.Ext(paths[0]))}
+	for _, path := range paths[1:] {
+		svc := gpx.NewService()
+		svc.SetElevationSource(src)
+		if _, err := svc.LoadAndProcess(path); err != nil {
+			return "", fmt.Errorf("%s: %v", filepath.Base(path), err)
+		}
+		others = append(others, svc)
+		names = append(names, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	}
+
+	// Chained into a new service so a rejected join keeps the current route
+	chained := gpx.NewService()
+	chained.SetElevationSource(src)
+	if _, err := chained.LoadAndProcess(paths[0]); err != nil {
+		return "", fmt.Errorf("%s: %v", filepath.Base(paths[0]), err)
+	}
+	if err := chained.Chain(others...); err != nil {
+		return "", err
+	}
+	a.gpxService = chained
+	return a.setEditedRoute(strings.Join(names, " + ")), nil
+}
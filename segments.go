@@ -0,0 +1,327 @@
+// Argus Cyclist - Virtual Cycling Environment for interactive bicycling experiments.
+// Copyright (C) 2026  Paulo Sérgio
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"argus-cyclist/internal/domain"
+	"argus-cyclist/internal/service/gpx"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// segmentMaxOffset is how far (m) a segment coordinate may be from the route.
+const segmentMaxOffset = 100.0
+
+// segmentTimer times the efforts on one segment during a session.
+type segmentTimer struct {
+	segment domain.Segment
+	best    float64 // PR time (s, 0 = none)
+
+	active    bool
+	startTime float64 // Active session time at the segment start
+	position  float64 // Last route position seen
+	ticks     int
+	powerSum  int
+	hrSum     int
+	hrTicks   int
+}
+
+// SegmentResult is emitted as "segment_completed" when an effort ends.
+type SegmentResult struct {
+	Segment  domain.Segment       `json:"segment"`
+	Effort   domain.SegmentEffort `json:"effort"`
+	PR       bool                 `json:"pr"`
+	Previous float64              `json:"previous"` // Previous PR time (s, 0 = first effort)
+}
+
+// segmentRouteSource identifies the current route for its segments: the
+// absolute path of the route file, as stored in every profile's library.
+// Generated and edited routes have no file and so no segments ("").
+func (a *App) segmentRouteSource() string {
+	path := a.gpxService.SourcePath()
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// loadSegmentTimers prepares timing for the segments of the current route.
+func (a *App) loadSegmentTimers() []*segmentTimer {
+	source := a.segmentRouteSource()
+	if source == "" {
+		return nil
+	}
+	segments, err := a.storageService.GetSegmentsForRoute(source)
+	if err != nil {
+		return nil
+	}
+	timers := make([]*segmentTimer, 0, len(segments))
+	for _, seg := range segments {
+		timers = append(timers, a.newSegmentTimer(seg))
+	}
+	return timers
+}
+
+func (a *App) newSegmentTimer(seg domain.Segment) *segmentTimer {
+	t := &segmentTimer{segment: seg}
+	if best, err := a.storageService.GetBestSegmentEffort(seg.ID); err == nil && best.ID != 0 {
+		t.best = best.Time
+	}
+	return t
+}
+
+// timeSegments advances the segment timers over one tick, from the route
+// position before the move to the one after it. Crossing times are
+// interpolated within the tick.
+func (a *App) timeSegments(before, after gpx.CoursePosition, total, dt float64, power int16, hr uint8) {
+	a.segmentsMu.Lock()
+	defer a.segmentsMu.Unlock()
+
+	tickEnd := a.sessionActiveTime
+	tickStart := tickEnd - dt
+
+	for _, t := range a.segments {
+		t.position = after.Position
+
+		// Segments are ridden forwards only: the return leg of an out-and-back cancels the effort
+		if after.Reversed || before.Reversed {
+			t.active = false
+			continue
+		}
+
+		switch {
+		case after.Lap == before.Lap:
+			a.advanceSegment(t, before.Position, after.Position, tickStart, tickEnd, power, hr)
+		case after.Lap == before.Lap+1:
+			// Crossed the end of the route: ride to the end, then (loop) on from the start
+			unwrapped := total - before.Position
+			if !after.Finished {
+				unwrapped += after.Position
+			}
+			split := tickEnd
+			if unwrapped > 0 {
+				split = tickStart + dt*(total-before.Position)/unwrapped
+			}
+			a.advanceSegment(t, before.Position, total, tickStart, split, power, hr)
+			if !after.Finished {
+				a.advanceSegment(t, 0, after.Position, split, tickEnd, power, hr)
+			}
+		default:
+			t.active = false
+			continue
+		}
+
+		if t.active {
+			t.sample(power, hr)
+		}
+	}
+}
+
+// advanceSegment moves a timer from one route position to a further one,
+// reached at the given active times. The tick that ends an effort is counted in it.
+func (a *App) advanceSegment(t *segmentTimer, from, to, timeFrom, timeTo float64, power int16, hr uint8) {
+	if to <= from {
+		return
+	}
+	seg := t.segment
+	at := func(pos float64) float64 {
+		return timeFrom + (timeTo-timeFrom)*(pos-from)/(to-from)
+	}
+
+	if !t.active && from <= seg.Start && to > seg.Start {
+		t.active = true
+		t.startTime = at(seg.Start)
+		t.ticks, t.powerSum, t.hrSum, t.hrTicks = 0, 0, 0, 0
+	}
+	if t.active && to >= seg.End {
+		t.sample(power, hr)
+		t.active = false
+		a.finishSegmentEffort(t, at(seg.End)-t.startTime)
+	}
+}
+
+// sample adds one telemetry tick to the effort in progress.
+func (t *segmentTimer) sample(power int16, hr uint8) {
+	t.ticks++
+	t.powerSum += int(power)
+	if hr > 0 {
+		t.hrSum += int(hr)
+		t.hrTicks++
+	}
+}
+
+// finishSegmentEffort stores a completed effort and announces it.
+func (a *App) finishSegmentEffort(t *segmentTimer, elapsed float64) {
+	effort := domain.SegmentEffort{
+		SegmentID: t.segment.ID,
+		Time:      elapsed,
+		AvgPower:  t.powerSum / max(t.ticks, 1),
+		Date:      time.Now(),
+	}
+	if weight := a.physicsEngine.UserWeight; weight > 0 {
+		effort.Wkg = float64(effort.AvgPower) / weight
+	}
+	if t.hrTicks > 0 {
+		effort.AvgHR = t.hrSum / t.hrTicks
+	}
+	if err := a.storageService.SaveSegmentEffort(&effort); err != nil {
+		fmt.Printf("[SIM] Segment effort not saved: %v\n", err)
+	}
+
+	result := SegmentResult{Segment: t.segment, Effort: effort, Previous: t.best}
+	if t.best == 0 || elapsed < t.best {
+		result.PR = true
+		t.best = elapsed
+	}
+	runtime.EventsEmit(a.ctx, "segment_completed", result)
+}
+
+// segmentTelemetry fills the live segment fields with the effort closest to its end.
+// The PR gap assumes the PR was ridden at an even pace along the segment.
+func (a *App) segmentTelemetry(t *domain.Telemetry) {
+	a.segmentsMu.Lock()
+	defer a.segmentsMu.Unlock()
+
+	var current *segmentTimer
+	for _, timer := range a.segments {
+		if timer.active && (current == nil || timer.segment.End-timer.position < current.segment.End-current.position) {
+			current = timer
+		}
+	}
+	if current == nil {
+		return
+	}
+
+	seg := current.segment
+	t.Segment = seg.Name
+	t.SegmentElapsed = a.sessionActiveTime - current.startTime
+	t.SegmentRemaining = seg.End - current.position
+	if current.best > 0 {
+		fraction := (current.position - seg.Start) / (seg.End - seg.Start)
+		t.SegmentPRGap = t.SegmentElapsed - current.best*fraction
+	}
+}
+
+// ========
+// SEGMENTS
+// ========
+
+// CreateSegment marks a timed segment on the current route between two
+// distances (m). The route must come from a file: segments are shared by
+// every profile that rides that file.
+func (a *App) CreateSegment(name string, start, end float64) (domain.Segment, error) {
+	if a.currentRouteName == "" || len(a.gpxService.GetAllPoints()) == 0 {
+		return domain.Segment{}, fmt.Errorf("no route loaded")
+	}
+	source := a.segmentRouteSource()
+	if source == "" {
+		return domain.Segment{}, fmt.Errorf("segments need a route loaded from a file")
+	}
+	if start < 0 || end <= start || end > a.gpxService.GetTotalDistance() {
+		return domain.Segment{}, fmt.Errorf("invalid segment range: %.0f-%.0f m", start, end)
+	}
+	if name == "" {
+		name = fmt.Sprintf("%.1f-%.1f km", start/1000, end/1000)
+	}
+
+	first := a.gpxService.GetPointAtDistance(start)
+	last := a.gpxService.GetPointAtDistance(end)
+	seg := domain.Segment{
+		RouteSource: source,
+		RouteName:   a.currentRouteName,
+		Name:        name,
+		Start:       start,
+		End:         end,
+		StartLat:    first.Latitude,
+		StartLon:    first.Longitude,
+		EndLat:      last.Latitude,
+		EndLon:      last.Longitude,
+	}
+	if err := a.storageService.SaveSegment(&seg); err != nil {
+		return seg, err
+	}
+	if a.isRecording {
+		timer := a.newSegmentTimer(seg)
+		a.segmentsMu.Lock()
+		a.segments = append(a.segments, timer)
+		a.segmentsMu.Unlock()
+	}
+	return seg, nil
+}
+
+// CreateSegmentByCoordinates marks a segment between the route points closest to two coordinates.
+func (a *App) CreateSegmentByCoordinates(name string, startLat, startLon, endLat, endLon float64) (domain.Segment, error) {
+	start, startOff := a.gpxService.NearestDistance(startLat, startLon)
+	end, endOff := a.gpxService.NearestDistance(endLat, endLon)
+	if startOff > segmentMaxOffset || endOff > segmentMaxOffset {
+		return domain.Segment{}, fmt.Errorf("the segment ends are not on the route")
+	}
+	return a.CreateSegment(name, start, end)
+}
+
+// GetRouteSegments lists the segments of the current route.
+func (a *App) GetRouteSegments() []domain.Segment {
+	source := a.segmentRouteSource()
+	if source == "" {
+		return nil
+	}
+	segments, _ := a.storageService.GetSegmentsForRoute(source)
+	return segments
+}
+
+// DeleteSegment removes every profile's efforts on a segment, then the
+// segment itself. If the efforts cannot all be removed the segment is kept,
+// so no effort is left without its segment.
+func (a *App) DeleteSegment(id uint) error {
+	if err := a.storageService.DeleteSegmentEfforts(id); err != nil {
+		return err
+	}
+	if err := a.storageService.DeleteSegment(id); err != nil {
+		return err
+	}
+
+	a.segmentsMu.Lock()
+	for i, t := range a.segments {
+		if t.segment.ID == id {
+			a.segments = append(a.segments[:i], a.segments[i+1:]...)
+			break
+		}
+	}
+	a.segmentsMu.Unlock()
+	return nil
+}
+
+// GetSegmentEfforts returns the rider's effort history on a segment, oldest first.
+func (a *App) GetSegmentEfforts(id uint) []domain.SegmentEffort {
+	efforts, _ := a.storageService.GetSegmentEfforts(id)
+	return efforts
+}
+
+// GetSegmentLeaderboard ranks the best effort of every local profile on a segment.
+func (a *App) GetSegmentLeaderboard(id uint) ([]domain.SegmentLeaderboardEntry, error) {
+	if _, err := a.storageService.GetSegmentByID(id); err != nil {
+		return nil, err
+	}
+	return a.storageService.GetSegmentLeaderboard(id, 20), nil
+}